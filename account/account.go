@@ -34,4 +34,7 @@ type Account struct {
 	Picture           string
 	Profile           string
 	UserID            UserID
+	// RelinkRequired is set when the upstream token can not be refreshed anymore,
+	// so the user has to login with the provider again
+	RelinkRequired bool
 }
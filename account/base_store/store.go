@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"slices"
+	"time"
 
 	"github.com/akatranlp/go-pkg/its"
 	"github.com/akatranlp/sentinel/account"
@@ -26,11 +27,17 @@ type UserIDAndPRoviderGetter interface {
 	GetAccountByUserIDAndProvider(ctx context.Context, id account.UserID, provider string) (account.Account, error)
 }
 
+// AccountExpiryGetter is implemented by repositories, which can find the accounts whose upstream tokens expire soon
+type AccountExpiryGetter interface {
+	GetAccountsExpiringBefore(ctx context.Context, t time.Time) ([]account.Account, error)
+}
+
 type BaseUserStore struct {
 	repo Repository
 }
 
 var _ (account.UserStore) = (*BaseUserStore)(nil)
+var _ (account.ExpiringAccountGetter) = (*BaseUserStore)(nil)
 
 func NewBaseUserStore(repo Repository) *BaseUserStore {
 	return &BaseUserStore{
@@ -190,3 +197,15 @@ func (s *BaseUserStore) UnLinkAccount(ctx context.Context, id account.UserID, ac
 
 	return nil
 }
+
+func (s *BaseUserStore) SupportsExpiringAccounts() bool {
+	_, ok := s.repo.(AccountExpiryGetter)
+	return ok
+}
+
+func (s *BaseUserStore) GetAccountsExpiringBefore(ctx context.Context, t time.Time) ([]account.Account, error) {
+	if spec, ok := s.repo.(AccountExpiryGetter); ok {
+		return spec.GetAccountsExpiringBefore(ctx, t)
+	}
+	return nil, errors.ErrUnsupported
+}
@@ -6,6 +6,8 @@ import (
 	"maps"
 	"os"
 	"slices"
+	"sync"
+	"time"
 
 	"github.com/akatranlp/go-pkg/its"
 	"github.com/akatranlp/sentinel/account"
@@ -15,6 +17,7 @@ import (
 type MemoryUserStore struct {
 	accounts map[account.AccountID]account.Account
 	users    map[account.UserID]account.User
+	mx       sync.Mutex
 	savePath string
 }
 
@@ -56,6 +59,7 @@ func (s *MemoryUserStore) UnmarshalJSON(data []byte) error {
 }
 
 var _ (account.UserStore) = (*MemoryUserStore)(nil)
+var _ (account.ExpiringAccountGetter) = (*MemoryUserStore)(nil)
 
 func NewMemoryUserStore(filePath ...string) (*MemoryUserStore, error) {
 	var path string
@@ -83,6 +87,8 @@ func NewMemoryUserStore(filePath ...string) (*MemoryUserStore, error) {
 }
 
 func (s *MemoryUserStore) GetUserByID(ctx context.Context, id account.UserID) (account.User, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	user, ok := s.users[id]
 	if !ok {
 		return user, account.ErrUserNotFound
@@ -91,6 +97,12 @@ func (s *MemoryUserStore) GetUserByID(ctx context.Context, id account.UserID) (a
 }
 
 func (s *MemoryUserStore) GetAccountsForUserID(ctx context.Context, id account.UserID) ([]account.Account, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.accountsForUserID(id)
+}
+
+func (s *MemoryUserStore) accountsForUserID(id account.UserID) ([]account.Account, error) {
 	if _, ok := s.users[id]; !ok {
 		return nil, account.ErrUserNotFound
 	}
@@ -106,6 +118,8 @@ func (s *MemoryUserStore) GetAccountsForUserID(ctx context.Context, id account.U
 }
 
 func (s *MemoryUserStore) GetUserByAccountID(ctx context.Context, id account.AccountID) (account.User, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	acc, ok := s.accounts[id]
 	if !ok {
 		return account.User{}, account.ErrAccountNotFound
@@ -119,6 +133,8 @@ func (s *MemoryUserStore) GetUserByAccountID(ctx context.Context, id account.Acc
 }
 
 func (s *MemoryUserStore) GetAccountByID(ctx context.Context, accID account.AccountID) (account.Account, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	acc, ok := s.accounts[accID]
 	if !ok {
 		return acc, account.ErrAccountNotFound
@@ -127,6 +143,8 @@ func (s *MemoryUserStore) GetAccountByID(ctx context.Context, accID account.Acco
 }
 
 func (s *MemoryUserStore) GetAccountByProvider(ctx context.Context, userID account.UserID, provider string) (account.Account, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	if _, ok := s.users[userID]; !ok {
 		return account.Account{}, account.ErrUserNotFound
 	}
@@ -141,6 +159,8 @@ func (s *MemoryUserStore) GetAccountByProvider(ctx context.Context, userID accou
 }
 
 func (s *MemoryUserStore) GetOrCreateUserFromAccount(ctx context.Context, acc account.Account) (account.User, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	if oldAcc, ok := s.accounts[acc.AccountID]; ok {
 		acc.UserID = oldAcc.UserID
 		s.accounts[acc.AccountID] = acc
@@ -172,6 +192,8 @@ func (s *MemoryUserStore) GetOrCreateUserFromAccount(ctx context.Context, acc ac
 }
 
 func (s *MemoryUserStore) UpdateUser(ctx context.Context, id account.UserID, user account.User) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	if _, ok := s.users[id]; !ok {
 		return account.ErrUserNotFound
 	}
@@ -182,6 +204,8 @@ func (s *MemoryUserStore) UpdateUser(ctx context.Context, id account.UserID, use
 }
 
 func (s *MemoryUserStore) UpdateAccount(ctx context.Context, id account.AccountID, acc account.Account) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	if _, ok := s.accounts[id]; !ok {
 		return account.ErrAccountNotFound
 	}
@@ -192,6 +216,8 @@ func (s *MemoryUserStore) UpdateAccount(ctx context.Context, id account.AccountI
 }
 
 func (s *MemoryUserStore) LinkAccount(ctx context.Context, id account.UserID, acc account.Account) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	if _, ok := s.users[id]; !ok {
 		return account.ErrUserNotFound
 	}
@@ -207,6 +233,8 @@ func (s *MemoryUserStore) LinkAccount(ctx context.Context, id account.UserID, ac
 }
 
 func (s *MemoryUserStore) UnLinkAccount(ctx context.Context, id account.UserID, accID account.AccountID) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
 	user, ok := s.users[id]
 	if !ok {
 		return account.ErrUserNotFound
@@ -221,7 +249,7 @@ func (s *MemoryUserStore) UnLinkAccount(ctx context.Context, id account.UserID,
 		return account.ErrAccountNotLinked
 	}
 
-	accounts, err := s.GetAccountsForUserID(ctx, id)
+	accounts, err := s.accountsForUserID(id)
 	if err != nil {
 		return err
 	}
@@ -231,7 +259,7 @@ func (s *MemoryUserStore) UnLinkAccount(ctx context.Context, id account.UserID,
 	}
 	delete(s.accounts, accID)
 
-	accounts, _ = s.GetAccountsForUserID(ctx, id)
+	accounts, _ = s.accountsForUserID(id)
 	newAcc := accounts[0]
 
 	var userNeedsUpdate bool
@@ -260,6 +288,18 @@ func (s *MemoryUserStore) UnLinkAccount(ctx context.Context, id account.UserID,
 	return nil
 }
 
+func (s *MemoryUserStore) SupportsExpiringAccounts() bool {
+	return true
+}
+
+func (s *MemoryUserStore) GetAccountsExpiringBefore(ctx context.Context, t time.Time) ([]account.Account, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return slices.Collect(its.Filter(maps.Values(s.accounts), func(acc account.Account) bool {
+		return !acc.Expiry.IsZero() && acc.Expiry.Before(t) && !acc.RelinkRequired
+	})), nil
+}
+
 func (s *MemoryUserStore) saveToFile() error {
 	if s.savePath == "" {
 		return nil
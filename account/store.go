@@ -3,6 +3,7 @@ package account
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
@@ -27,3 +28,11 @@ type UserStore interface {
 	LinkAccount(ctx context.Context, id UserID, acc Account) error
 	UnLinkAccount(ctx context.Context, id UserID, accID AccountID) error
 }
+
+// ExpiringAccountGetter is implemented by user stores, which can find the accounts whose upstream tokens expire soon.
+// Accounts without expiry or which have to be linked again are not returned.
+type ExpiringAccountGetter interface {
+	// SupportsExpiringAccounts reports if the accounts can be found, stores which depend on a repository may not support it
+	SupportsExpiringAccounts() bool
+	GetAccountsExpiringBefore(ctx context.Context, t time.Time) ([]Account, error)
+}
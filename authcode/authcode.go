@@ -0,0 +1,54 @@
+package authcode
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akatranlp/sentinel/openid/types"
+)
+
+var (
+	ErrAuthCodeNotFound = errors.New("authorization code not found")
+	ErrAuthCodeRedeemed = errors.New("authorization code already redeemed")
+)
+
+// AuthCode holds the authorization, which the client redeems with the code at the token endpoint
+type AuthCode struct {
+	Code        string
+	AuthRequest types.AuthRequest
+	UserID      string
+	AuthTime    time.Time
+	ACR         string
+	AMR         []string
+	// SessionID is the sid of the tokens, which the browser session already knows
+	SessionID string
+	Expiry    time.Time
+	// RedeemedAt is set when the code is consumed.
+	// Redeemed codes are kept until RetainUntil, so a replay can be detected.
+	RedeemedAt time.Time
+	// ReplayedAt is set when the redeemed code is used again.
+	// Tokens, which are still created for the code, have to be revoked.
+	ReplayedAt  time.Time
+	RetainUntil time.Time
+}
+
+// IsRetained reports if the store has to keep the code at the given time
+func (c AuthCode) IsRetained(curr time.Time) bool {
+	return curr.Before(c.Expiry) || curr.Before(c.RetainUntil)
+}
+
+type AuthCodeStore interface {
+	CreateAuthCode(ctx context.Context, code AuthCode) error
+	// GetAuthCode returns the code without redeeming it, so the request can be checked first.
+	// Redeemed codes are returned as well, until they are not retained anymore.
+	GetAuthCode(ctx context.Context, code string) (AuthCode, error)
+	// ConsumeAuthCode marks the code as redeemed and returns it in one atomic step,
+	// so a code can only be redeemed once, even by multiple instances.
+	// Expired codes are not returned.
+	// If the code was already redeemed, it is returned with ErrAuthCodeRedeemed.
+	ConsumeAuthCode(ctx context.Context, code string) (AuthCode, error)
+	// MarkAuthCodeReplayed sets ReplayedAt of a redeemed code,
+	// so a token request, which redeemed the code concurrently, does not issue tokens for it.
+	MarkAuthCodeReplayed(ctx context.Context, code string) error
+}
@@ -0,0 +1,89 @@
+package basestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/akatranlp/sentinel/authcode"
+)
+
+type Repository interface {
+	CreateAuthCode(ctx context.Context, code authcode.AuthCode) error
+	GetAuthCode(ctx context.Context, code string) (authcode.AuthCode, error)
+	// RedeemAuthCode sets RedeemedAt of the code, if it is not set yet, and returns the code as it was before.
+	// This has to be atomic, so only one of concurrent requests gets the code without RedeemedAt.
+	RedeemAuthCode(ctx context.Context, code string) (authcode.AuthCode, error)
+	// MarkAuthCodeReplayed sets ReplayedAt of the code, if it is not set yet
+	MarkAuthCodeReplayed(ctx context.Context, code string) error
+}
+
+// AuthCodeDeleter deletes the codes, which are not retained anymore
+type AuthCodeDeleter interface {
+	DeleteAuthCodesAfterRetention(ctx context.Context) error
+}
+
+type BaseAuthCodeStore struct {
+	repo Repository
+}
+
+var _ (authcode.AuthCodeStore) = (*BaseAuthCodeStore)(nil)
+
+func NewBaseAuthCodeStore(repo Repository) *BaseAuthCodeStore {
+	return &BaseAuthCodeStore{
+		repo: repo,
+	}
+}
+
+func (s *BaseAuthCodeStore) CreateAuthCode(ctx context.Context, code authcode.AuthCode) error {
+	return s.repo.CreateAuthCode(ctx, code)
+}
+
+func (s *BaseAuthCodeStore) GetAuthCode(ctx context.Context, code string) (authcode.AuthCode, error) {
+	authCode, err := s.repo.GetAuthCode(ctx, code)
+	if err != nil {
+		return authcode.AuthCode{}, err
+	}
+	if !authCode.IsRetained(time.Now()) {
+		return authcode.AuthCode{}, authcode.ErrAuthCodeNotFound
+	}
+	return authCode, nil
+}
+
+func (s *BaseAuthCodeStore) ConsumeAuthCode(ctx context.Context, code string) (authcode.AuthCode, error) {
+	authCode, err := s.repo.RedeemAuthCode(ctx, code)
+	if err != nil {
+		return authcode.AuthCode{}, err
+	}
+	if !authCode.RedeemedAt.IsZero() {
+		return authCode, authcode.ErrAuthCodeRedeemed
+	}
+	if authCode.Expiry.Before(time.Now()) {
+		return authcode.AuthCode{}, authcode.ErrAuthCodeNotFound
+	}
+	return authCode, nil
+}
+
+func (s *BaseAuthCodeStore) MarkAuthCodeReplayed(ctx context.Context, code string) error {
+	return s.repo.MarkAuthCodeReplayed(ctx, code)
+}
+
+// StartCodeCleanup deletes the codes, which are not retained anymore.
+// Only repositories which can delete the expired codes are cleaned up.
+func (s *BaseAuthCodeStore) StartCodeCleanup(ctx context.Context) error {
+	spec, ok := s.repo.(AuthCodeDeleter)
+	if !ok {
+		return nil
+	}
+	go func() {
+		ticker := time.Tick(5 * time.Minute)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker:
+				spec.DeleteAuthCodesAfterRetention(ctx)
+			}
+		}
+	}()
+	return nil
+}
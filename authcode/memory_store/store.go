@@ -0,0 +1,188 @@
+package memorystore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/akatranlp/sentinel/authcode"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+)
+
+type MemoryAuthCodeStore struct {
+	codes    map[string]authcode.AuthCode
+	mx       sync.Mutex
+	savePath string
+}
+
+// storedAuthRequest replaces the enums of the auth request, which reject empty values
+type storedAuthRequest struct {
+	types.AuthRequest
+	ResponseType        string `json:",omitempty"`
+	ResponseMode        string `json:",omitempty"`
+	CodeChallengeMethod string `json:",omitempty"`
+	Display             string `json:",omitempty"`
+	Prompt              string `json:",omitempty"`
+}
+
+type storedAuthCode struct {
+	authcode.AuthCode
+	AuthRequest storedAuthRequest
+}
+
+type marshal struct {
+	Codes map[string]storedAuthCode `json:"codes"`
+}
+
+func (s *MemoryAuthCodeStore) MarshalJSON() ([]byte, error) {
+	codes := make(map[string]storedAuthCode, len(s.codes))
+	for code, authCode := range s.codes {
+		authReq := authCode.AuthRequest
+		codes[code] = storedAuthCode{
+			AuthCode: authCode,
+			AuthRequest: storedAuthRequest{
+				AuthRequest:         authReq,
+				ResponseType:        authReq.ResponseType.String(),
+				ResponseMode:        authReq.ResponseMode.String(),
+				CodeChallengeMethod: authReq.CodeChallengeMethod.String(),
+				Display:             authReq.Display.String(),
+				Prompt:              authReq.Prompt.String(),
+			},
+		}
+	}
+	return json.Marshal(marshal{
+		Codes: codes,
+	})
+}
+
+func (s *MemoryAuthCodeStore) UnmarshalJSON(data []byte) error {
+	var store marshal
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return err
+	}
+
+	codes := make(map[string]authcode.AuthCode, len(store.Codes))
+	for code, stored := range store.Codes {
+		authCode := stored.AuthCode
+		authCode.AuthRequest = stored.AuthRequest.AuthRequest
+		authCode.AuthRequest.ResponseType = enums.ResponseType(stored.AuthRequest.ResponseType)
+		authCode.AuthRequest.ResponseMode = enums.ResponseMode(stored.AuthRequest.ResponseMode)
+		authCode.AuthRequest.CodeChallengeMethod = enums.CodeChallengeMethod(stored.AuthRequest.CodeChallengeMethod)
+		authCode.AuthRequest.Display = enums.Display(stored.AuthRequest.Display)
+		authCode.AuthRequest.Prompt = enums.Prompt(stored.AuthRequest.Prompt)
+		codes[code] = authCode
+	}
+
+	*s = MemoryAuthCodeStore{
+		codes: codes,
+	}
+	return nil
+}
+
+var _ (authcode.AuthCodeStore) = (*MemoryAuthCodeStore)(nil)
+
+func NewMemoryAuthCodeStore(filePath ...string) (*MemoryAuthCodeStore, error) {
+	var path string
+	if len(filePath) > 0 {
+		path = filePath[0]
+	}
+	if path != "" {
+		f, err := os.Open(path)
+		if err == nil {
+			defer f.Close()
+			var store MemoryAuthCodeStore
+			if err := json.NewDecoder(f).Decode(&store); err != nil {
+				return nil, err
+			}
+			store.savePath = path
+			return &store, nil
+		}
+	}
+	return &MemoryAuthCodeStore{
+		codes:    make(map[string]authcode.AuthCode),
+		savePath: path,
+	}, nil
+}
+
+func (s *MemoryAuthCodeStore) CreateAuthCode(ctx context.Context, code authcode.AuthCode) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	// codes are short-lived, so the expired ones are dropped here instead of in a cleanup job
+	s.deleteExpiredCodes(time.Now())
+	s.codes[code.Code] = code
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryAuthCodeStore) GetAuthCode(ctx context.Context, code string) (authcode.AuthCode, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	authCode, ok := s.codes[code]
+	if !ok || !authCode.IsRetained(time.Now()) {
+		return authcode.AuthCode{}, authcode.ErrAuthCodeNotFound
+	}
+	return authCode, nil
+}
+
+func (s *MemoryAuthCodeStore) ConsumeAuthCode(ctx context.Context, code string) (authcode.AuthCode, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	authCode, ok := s.codes[code]
+	if !ok {
+		return authcode.AuthCode{}, authcode.ErrAuthCodeNotFound
+	}
+	if !authCode.RedeemedAt.IsZero() {
+		return authCode, authcode.ErrAuthCodeRedeemed
+	}
+	curr := time.Now()
+	if authCode.Expiry.Before(curr) {
+		delete(s.codes, code)
+		s.saveToFile()
+		return authcode.AuthCode{}, authcode.ErrAuthCodeNotFound
+	}
+	authCode.RedeemedAt = curr
+	s.codes[code] = authCode
+	s.saveToFile()
+	return authCode, nil
+}
+
+func (s *MemoryAuthCodeStore) MarkAuthCodeReplayed(ctx context.Context, code string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	authCode, ok := s.codes[code]
+	if !ok {
+		return authcode.ErrAuthCodeNotFound
+	}
+	if authCode.ReplayedAt.IsZero() {
+		authCode.ReplayedAt = time.Now()
+		s.codes[code] = authCode
+		s.saveToFile()
+	}
+	return nil
+}
+
+func (s *MemoryAuthCodeStore) deleteExpiredCodes(curr time.Time) {
+	for code, authCode := range s.codes {
+		if !authCode.IsRetained(curr) {
+			delete(s.codes, code)
+		}
+	}
+}
+
+func (s *MemoryAuthCodeStore) saveToFile() error {
+	if s.savePath == "" {
+		return nil
+	}
+	f, err := os.Create(s.savePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
@@ -0,0 +1,98 @@
+package basestore
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/akatranlp/sentinel/client"
+)
+
+type Repository interface {
+	GetClientByID(ctx context.Context, clientID string) (client.Client, error)
+	UpdateClient(ctx context.Context, c client.Client) error
+	DeleteClientByID(ctx context.Context, clientID string) error
+}
+
+type ClientCreater interface {
+	CreateClient(ctx context.Context, c client.Client) (client.Client, error)
+}
+
+type ClientSetter interface {
+	SetClient(ctx context.Context, c client.Client) error
+}
+
+// OriginChecker lets the repository answer the cors check with a query instead of loading all clients
+type OriginChecker interface {
+	IsAllowedOrigin(ctx context.Context, origin string) (bool, error)
+}
+
+type ClientGetter interface {
+	GetAllClients(ctx context.Context) ([]client.Client, error)
+}
+
+type BaseClientStore struct {
+	repo Repository
+}
+
+var _ (client.ClientStore) = (*BaseClientStore)(nil)
+
+func NewBaseClientStore(repo Repository) (*BaseClientStore, error) {
+	_, ok1 := repo.(OriginChecker)
+	_, ok2 := repo.(ClientGetter)
+	if !ok1 && !ok2 {
+		return nil, errors.New("repo must be origin checker or getter")
+	}
+
+	_, ok1 = repo.(ClientCreater)
+	_, ok2 = repo.(ClientSetter)
+	if !ok1 && !ok2 {
+		return nil, errors.New("repo must be creater or setter")
+	}
+
+	return &BaseClientStore{
+		repo: repo,
+	}, nil
+}
+
+func (s *BaseClientStore) GetClient(ctx context.Context, clientID string) (client.Client, error) {
+	return s.repo.GetClientByID(ctx, clientID)
+}
+
+func (s *BaseClientStore) SetClient(ctx context.Context, c client.Client) error {
+	if spec, ok := s.repo.(ClientSetter); ok {
+		return spec.SetClient(ctx, c)
+	}
+
+	spec := s.repo.(ClientCreater)
+
+	_, err := s.repo.GetClientByID(ctx, c.ClientID)
+	if err == nil {
+		return s.repo.UpdateClient(ctx, c)
+	} else if !errors.Is(err, client.ErrClientNotFound) {
+		return err
+	}
+	_, err = spec.CreateClient(ctx, c)
+	return err
+}
+
+func (s *BaseClientStore) DeleteClient(ctx context.Context, clientID string) error {
+	return s.repo.DeleteClientByID(ctx, clientID)
+}
+
+func (s *BaseClientStore) IsAllowedOrigin(ctx context.Context, origin string) (bool, error) {
+	if spec, ok := s.repo.(OriginChecker); ok {
+		return spec.IsAllowedOrigin(ctx, origin)
+	}
+
+	clients, err := s.repo.(ClientGetter).GetAllClients(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range clients {
+		if slices.Contains(c.Origins(), origin) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
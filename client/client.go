@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+var (
+	ErrClientNotFound = errors.New("client not found")
+)
+
+type Client struct {
+	ClientID               string
+	ClientSecret           string
+	ClientName             string
+	Scope                  enums.Scopes
+	RedirectURIs           []string
+	PostLogoutRedirectURIs []string
+	// GrantTypes restricts the grants the client can use, if it is set
+	GrantTypes []enums.GrantType
+	// TokenEndpointAuthMethod restricts the client to this authentication method, if it is set
+	TokenEndpointAuthMethod enums.EndpointAuthMethod
+	// JWKS contains the public keys of the client, which are used to verify
+	// signed request objects and private_key_jwt client assertions
+	JWKS jwk.Set
+	// JWKSURI is used to fetch the public keys of the client if no JWKS is set
+	JWKSURI string
+	// TLSClientAuthSubjectDN is the expected subject of the client certificate for tls_client_auth
+	TLSClientAuthSubjectDN string
+	// RequirePushedAuthorizationRequests only allows authorization requests
+	// which were pushed to the par endpoint before
+	RequirePushedAuthorizationRequests bool
+	// BackchannelLogoutURI receives a logout token when a session of the client ends
+	BackchannelLogoutURI string
+	// BackchannelLogoutSessionRequired requires the sid claim in the logout token
+	BackchannelLogoutSessionRequired bool
+	// FrontchannelLogoutURI is loaded in an iframe by the browser of the user on logout
+	FrontchannelLogoutURI string
+	// SubjectType is pairwise if the client gets its own subject identifiers for the users
+	SubjectType enums.SubjectType
+	// SectorIdentifierURI lists the redirect uris of all clients which share the pairwise subject identifiers
+	SectorIdentifierURI string
+	// TokenExchangePolicy allows the client to use the token exchange grant, if it is set
+	TokenExchangePolicy *TokenExchangePolicy
+	// FirstParty clients belong to the operator of the identity provider,
+	// so the users do not have to consent to their requests
+	FirstParty bool
+
+	// These are only set for dynamically registered clients
+	ClientIDIssuedAt            time.Time
+	RegistrationAccessTokenHash string
+}
+
+// TokenExchangePolicy restricts the token exchanges of a client (RFC 8693)
+type TokenExchangePolicy struct {
+	// SubjectTokenTypes are the types of the tokens the client can exchange
+	SubjectTokenTypes []enums.OauthTokenType
+	// Audiences are the clients and resources the client can request tokens for
+	Audiences []string
+	// Providers are the slugs of the providers, whose tokens of the user the client can request with requested_issuer
+	Providers []string
+	// Delegation allows actor tokens, so the client can act on behalf of the subject
+	Delegation bool
+	// Impersonation allows exchanges without actor tokens, so the client can act as the subject
+	Impersonation bool
+}
+
+// AllowsSubjectTokenType reports if the client can exchange tokens of this type
+func (p *TokenExchangePolicy) AllowsSubjectTokenType(tokenType enums.OauthTokenType) bool {
+	return slices.Contains(p.SubjectTokenTypes, tokenType)
+}
+
+// AllowsAudience reports if the client can request tokens for the audience
+func (p *TokenExchangePolicy) AllowsAudience(audience string) bool {
+	return slices.Contains(p.Audiences, audience)
+}
+
+// AllowsProvider reports if the client can request the tokens of the user from the provider
+func (p *TokenExchangePolicy) AllowsProvider(slug string) bool {
+	return slices.Contains(p.Providers, slug)
+}
+
+// IsPublic reports if the client has no credentials to authenticate itself
+func (r *Client) IsPublic() bool {
+	return r.ClientSecret == "" && r.JWKS == nil && r.JWKSURI == "" && r.TLSClientAuthSubjectDN == ""
+}
+
+func (r *Client) CheckGrantType(grantType enums.GrantType) bool {
+	return len(r.GrantTypes) == 0 || slices.Contains(r.GrantTypes, grantType)
+}
+
+func (r *Client) CheckRedirectURI(rURI url.URL) error {
+	hostname := rURI.Hostname()
+	switch hostname {
+	case "localhost", "127.0.0.1", "::1":
+		rURI.Host = hostname
+	}
+
+	if !slices.Contains(r.RedirectURIs, rURI.String()) {
+		return errors.New("invalid redirect uri")
+	}
+
+	return nil
+}
+
+func (r *Client) CheckPostLogoutRedirectURI(rURI url.URL) error {
+	hostname := rURI.Hostname()
+	switch hostname {
+	case "localhost", "127.0.0.1", "::1":
+		rURI.Host = hostname
+	}
+
+	if !slices.Contains(r.PostLogoutRedirectURIs, rURI.String()) {
+		return errors.New("invalid redirect uri")
+	}
+
+	return nil
+}
+
+// SectorIdentifier returns the host the pairwise subject identifiers of the client are calculated for.
+// Without a sector identifier uri the host of the redirect uris is used.
+func (r *Client) SectorIdentifier() string {
+	if sectorIdentifierURI, err := url.Parse(r.SectorIdentifierURI); err == nil && sectorIdentifierURI.Host != "" {
+		return sectorIdentifierURI.Host
+	}
+	for _, redirectURI := range r.RedirectURIs {
+		if rURI, err := url.Parse(redirectURI); err == nil && rURI.Host != "" {
+			return rURI.Host
+		}
+	}
+	return r.ClientID
+}
+
+// Origins returns the origins of the redirect uris, which are allowed for cross-origin requests.
+// Loopback origins are stored without their port like the redirect uris are compared.
+func (r *Client) Origins() []string {
+	var origins []string
+	for _, redirectURI := range r.RedirectURIs {
+		rURI, err := url.Parse(redirectURI)
+		if err != nil || rURI.Scheme == "" || rURI.Host == "" {
+			continue
+		}
+		if origin := Origin(*rURI); !slices.Contains(origins, origin) {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// Origin returns the origin of the url in the form the client origins are stored
+func Origin(u url.URL) string {
+	host := u.Host
+	switch hostname := u.Hostname(); hostname {
+	case "localhost", "127.0.0.1", "::1":
+		host = hostname
+	}
+	return u.Scheme + "://" + host
+}
+
+type ClientStore interface {
+	GetClient(ctx context.Context, clientID string) (Client, error)
+	// SetClient creates the client or replaces it, if a client with the same id already exists
+	SetClient(ctx context.Context, client Client) error
+	DeleteClient(ctx context.Context, clientID string) error
+	// IsAllowedOrigin reports if the origin belongs to a redirect uri of any client.
+	// The origin has to be normalized with Origin before.
+	IsAllowedOrigin(ctx context.Context, origin string) (bool, error)
+}
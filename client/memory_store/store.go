@@ -0,0 +1,170 @@
+package memorystore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+type MemoryClientStore struct {
+	clients map[string]client.Client
+	// origins is rebuilt on every change, so the cors check does not have to walk all clients
+	origins  map[string]struct{}
+	mx       sync.RWMutex
+	savePath string
+}
+
+// storedClient replaces the fields of the client which can not be unmarshaled,
+// like the jwk.Set interface and the enums, which reject empty values
+type storedClient struct {
+	client.Client
+	JWKS                    json.RawMessage `json:",omitempty"`
+	TokenEndpointAuthMethod string          `json:",omitempty"`
+	SubjectType             string          `json:",omitempty"`
+}
+
+type marshal struct {
+	Clients map[string]storedClient `json:"clients"`
+}
+
+func (s *MemoryClientStore) MarshalJSON() ([]byte, error) {
+	clients := make(map[string]storedClient, len(s.clients))
+	for clientID, c := range s.clients {
+		stored := storedClient{
+			Client:                  c,
+			TokenEndpointAuthMethod: c.TokenEndpointAuthMethod.String(),
+			SubjectType:             c.SubjectType.String(),
+		}
+		if c.JWKS != nil {
+			jwks, err := json.Marshal(c.JWKS)
+			if err != nil {
+				return nil, err
+			}
+			stored.JWKS = jwks
+		}
+		clients[clientID] = stored
+	}
+	return json.Marshal(marshal{
+		Clients: clients,
+	})
+}
+
+func (s *MemoryClientStore) UnmarshalJSON(data []byte) error {
+	var store marshal
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return err
+	}
+
+	clients := make(map[string]client.Client, len(store.Clients))
+	for clientID, stored := range store.Clients {
+		c := stored.Client
+		c.TokenEndpointAuthMethod = enums.EndpointAuthMethod(stored.TokenEndpointAuthMethod)
+		c.SubjectType = enums.SubjectType(stored.SubjectType)
+		if len(stored.JWKS) != 0 {
+			set, err := jwk.Parse(stored.JWKS)
+			if err != nil {
+				return err
+			}
+			c.JWKS = set
+		}
+		clients[clientID] = c
+	}
+
+	*s = MemoryClientStore{
+		clients: clients,
+	}
+	s.buildOrigins()
+	return nil
+}
+
+var _ (client.ClientStore) = (*MemoryClientStore)(nil)
+
+func NewMemoryClientStore(filePath ...string) (*MemoryClientStore, error) {
+	var path string
+	if len(filePath) > 0 {
+		path = filePath[0]
+	}
+	if path != "" {
+		f, err := os.Open(path)
+		if err == nil {
+			defer f.Close()
+			var store MemoryClientStore
+			if err := json.NewDecoder(f).Decode(&store); err != nil {
+				return nil, err
+			}
+			store.savePath = path
+			return &store, nil
+		}
+	}
+	return &MemoryClientStore{
+		clients:  make(map[string]client.Client),
+		origins:  make(map[string]struct{}),
+		savePath: path,
+	}, nil
+}
+
+func (s *MemoryClientStore) GetClient(ctx context.Context, clientID string) (client.Client, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	c, ok := s.clients[clientID]
+	if !ok {
+		return client.Client{}, client.ErrClientNotFound
+	}
+	return c, nil
+}
+
+func (s *MemoryClientStore) SetClient(ctx context.Context, c client.Client) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.clients[c.ClientID] = c
+	s.buildOrigins()
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryClientStore) DeleteClient(ctx context.Context, clientID string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if _, ok := s.clients[clientID]; !ok {
+		return client.ErrClientNotFound
+	}
+	delete(s.clients, clientID)
+	s.buildOrigins()
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryClientStore) IsAllowedOrigin(ctx context.Context, origin string) (bool, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	_, ok := s.origins[origin]
+	return ok, nil
+}
+
+func (s *MemoryClientStore) buildOrigins() {
+	s.origins = make(map[string]struct{})
+	for _, c := range s.clients {
+		for _, origin := range c.Origins() {
+			s.origins[origin] = struct{}{}
+		}
+	}
+}
+
+func (s *MemoryClientStore) saveToFile() error {
+	if s.savePath == "" {
+		return nil
+	}
+	f, err := os.Create(s.savePath)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
@@ -0,0 +1,39 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+var (
+	ErrGrantNotFound = errors.New("grant not found")
+)
+
+// Grant holds the scopes a user has allowed a client to access
+type Grant struct {
+	UserID    string
+	ClientID  string
+	Scope     enums.Scopes
+	GrantedAt time.Time
+}
+
+// Covers reports if all requested scopes were already granted
+func (g *Grant) Covers(scope enums.Scopes) bool {
+	for _, s := range scope {
+		if !slices.Contains(g.Scope, s) {
+			return false
+		}
+	}
+	return true
+}
+
+type GrantStore interface {
+	GetGrant(ctx context.Context, userID, clientID string) (Grant, error)
+	// SetGrant creates the grant or replaces the one of the same user and client
+	SetGrant(ctx context.Context, grant Grant) error
+	RevokeGrant(ctx context.Context, userID, clientID string) error
+}
@@ -0,0 +1,113 @@
+package memorystore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/akatranlp/sentinel/consent"
+)
+
+type MemoryGrantStore struct {
+	// grants are stored by user id and client id
+	grants   map[string]map[string]consent.Grant
+	mx       sync.Mutex
+	savePath string
+}
+
+type marshal struct {
+	Grants map[string]map[string]consent.Grant `json:"grants"`
+}
+
+func (s *MemoryGrantStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(marshal{
+		Grants: s.grants,
+	})
+}
+
+func (s *MemoryGrantStore) UnmarshalJSON(data []byte) error {
+	var store marshal
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return err
+	}
+
+	*s = MemoryGrantStore{
+		grants: store.Grants,
+	}
+	return nil
+}
+
+var _ (consent.GrantStore) = (*MemoryGrantStore)(nil)
+
+func NewMemoryGrantStore(filePath ...string) (*MemoryGrantStore, error) {
+	var path string
+	if len(filePath) > 0 {
+		path = filePath[0]
+	}
+	if path != "" {
+		f, err := os.Open(path)
+		if err == nil {
+			defer f.Close()
+			var store MemoryGrantStore
+			if err := json.NewDecoder(f).Decode(&store); err != nil {
+				return nil, err
+			}
+			store.savePath = path
+			return &store, nil
+		}
+	}
+	return &MemoryGrantStore{
+		grants:   make(map[string]map[string]consent.Grant),
+		savePath: path,
+	}, nil
+}
+
+func (s *MemoryGrantStore) GetGrant(ctx context.Context, userID, clientID string) (consent.Grant, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	grant, ok := s.grants[userID][clientID]
+	if !ok {
+		return consent.Grant{}, consent.ErrGrantNotFound
+	}
+	return grant, nil
+}
+
+func (s *MemoryGrantStore) SetGrant(ctx context.Context, grant consent.Grant) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if _, ok := s.grants[grant.UserID]; !ok {
+		s.grants[grant.UserID] = make(map[string]consent.Grant)
+	}
+	s.grants[grant.UserID][grant.ClientID] = grant
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryGrantStore) RevokeGrant(ctx context.Context, userID, clientID string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if _, ok := s.grants[userID][clientID]; !ok {
+		return consent.ErrGrantNotFound
+	}
+	delete(s.grants[userID], clientID)
+	if len(s.grants[userID]) == 0 {
+		delete(s.grants, userID)
+	}
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryGrantStore) saveToFile() error {
+	if s.savePath == "" {
+		return nil
+	}
+	f, err := os.Create(s.savePath)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
@@ -0,0 +1,43 @@
+//go:generate go tool go-enum --marshal
+package device
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+var (
+	ErrDeviceCodeNotFound = errors.New("device code not found")
+	// ErrDeviceCodeNotPending is returned if the user has already approved or denied the device code
+	ErrDeviceCodeNotPending = errors.New("device code is not pending")
+)
+
+// ENUM(pending, approved, denied)
+type Status string
+
+type DeviceCode struct {
+	DeviceCode string
+	UserCode   string
+	ClientID   string
+	Scope      enums.Scopes
+	Status     Status
+	UserID     string
+	AuthTime   time.Time
+	Interval   time.Duration
+	LastPoll   time.Time
+	Expiry     time.Time
+}
+
+type DeviceCodeStore interface {
+	CreateDeviceCode(ctx context.Context, code DeviceCode) error
+	GetDeviceCode(ctx context.Context, deviceCode string) (DeviceCode, error)
+	GetDeviceCodeByUserCode(ctx context.Context, userCode string) (DeviceCode, error)
+	// UpdateDeviceCodePoll only updates the polling state of the device, so it does not overwrite the decision of the user
+	UpdateDeviceCodePoll(ctx context.Context, deviceCode string, lastPoll time.Time, interval time.Duration) error
+	// UpdateDeviceCodeStatus sets the decision of the user and fails with ErrDeviceCodeNotPending if the code was already decided
+	UpdateDeviceCodeStatus(ctx context.Context, deviceCode string, status Status, userID string, authTime time.Time) error
+	DeleteDeviceCode(ctx context.Context, deviceCode string) error
+}
@@ -0,0 +1,64 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package device
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// StatusPending is a Status of type pending.
+	StatusPending Status = "pending"
+	// StatusApproved is a Status of type approved.
+	StatusApproved Status = "approved"
+	// StatusDenied is a Status of type denied.
+	StatusDenied Status = "denied"
+)
+
+var ErrInvalidStatus = errors.New("not a valid Status")
+
+// String implements the Stringer interface.
+func (x Status) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Status) IsValid() bool {
+	_, err := ParseStatus(string(x))
+	return err == nil
+}
+
+var _StatusValue = map[string]Status{
+	"pending":  StatusPending,
+	"approved": StatusApproved,
+	"denied":   StatusDenied,
+}
+
+// ParseStatus attempts to convert a string to a Status.
+func ParseStatus(name string) (Status, error) {
+	if x, ok := _StatusValue[name]; ok {
+		return x, nil
+	}
+	return Status(""), fmt.Errorf("%s is %w", name, ErrInvalidStatus)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Status) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Status) UnmarshalText(text []byte) error {
+	tmp, err := ParseStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -0,0 +1,176 @@
+package memorystore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/akatranlp/sentinel/device"
+)
+
+type MemoryDeviceCodeStore struct {
+	codes    map[string]device.DeviceCode
+	mx       sync.Mutex
+	savePath string
+}
+
+type marshal struct {
+	Codes map[string]device.DeviceCode `json:"codes"`
+}
+
+func (s *MemoryDeviceCodeStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(marshal{
+		Codes: s.codes,
+	})
+}
+
+func (s *MemoryDeviceCodeStore) UnmarshalJSON(data []byte) error {
+	var store marshal
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return err
+	}
+
+	*s = MemoryDeviceCodeStore{
+		codes: store.Codes,
+	}
+	return nil
+}
+
+var _ (device.DeviceCodeStore) = (*MemoryDeviceCodeStore)(nil)
+
+func NewMemoryDeviceCodeStore(filePath ...string) (*MemoryDeviceCodeStore, error) {
+	var path string
+	if len(filePath) > 0 {
+		path = filePath[0]
+	}
+	if path != "" {
+		f, err := os.Open(path)
+		if err == nil {
+			defer f.Close()
+			var store MemoryDeviceCodeStore
+			if err := json.NewDecoder(f).Decode(&store); err != nil {
+				return nil, err
+			}
+			store.savePath = path
+			return &store, nil
+		}
+	}
+	return &MemoryDeviceCodeStore{
+		codes:    make(map[string]device.DeviceCode),
+		savePath: path,
+	}, nil
+}
+
+func (s *MemoryDeviceCodeStore) CreateDeviceCode(ctx context.Context, code device.DeviceCode) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.codes[code.DeviceCode] = code
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryDeviceCodeStore) GetDeviceCode(ctx context.Context, deviceCode string) (device.DeviceCode, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	code, ok := s.codes[deviceCode]
+	if !ok {
+		return device.DeviceCode{}, device.ErrDeviceCodeNotFound
+	}
+	return code, nil
+}
+
+func (s *MemoryDeviceCodeStore) GetDeviceCodeByUserCode(ctx context.Context, userCode string) (device.DeviceCode, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for _, code := range s.codes {
+		if code.UserCode == userCode {
+			return code, nil
+		}
+	}
+	return device.DeviceCode{}, device.ErrDeviceCodeNotFound
+}
+
+func (s *MemoryDeviceCodeStore) UpdateDeviceCodePoll(ctx context.Context, deviceCode string, lastPoll time.Time, interval time.Duration) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	code, ok := s.codes[deviceCode]
+	if !ok {
+		return device.ErrDeviceCodeNotFound
+	}
+	code.LastPoll = lastPoll
+	code.Interval = interval
+	s.codes[deviceCode] = code
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryDeviceCodeStore) UpdateDeviceCodeStatus(ctx context.Context, deviceCode string, status device.Status, userID string, authTime time.Time) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	code, ok := s.codes[deviceCode]
+	if !ok {
+		return device.ErrDeviceCodeNotFound
+	}
+	if code.Status != device.StatusPending {
+		return device.ErrDeviceCodeNotPending
+	}
+	code.Status = status
+	code.UserID = userID
+	code.AuthTime = authTime
+	s.codes[deviceCode] = code
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryDeviceCodeStore) DeleteDeviceCode(ctx context.Context, deviceCode string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.deleteDeviceCode(ctx, deviceCode)
+}
+
+func (s *MemoryDeviceCodeStore) deleteDeviceCode(_ context.Context, deviceCode string) error {
+	if _, ok := s.codes[deviceCode]; !ok {
+		return device.ErrDeviceCodeNotFound
+	}
+	delete(s.codes, deviceCode)
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryDeviceCodeStore) StartCodeCleanup(ctx context.Context) {
+	go func() {
+		ticker := time.Tick(5 * time.Minute)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker:
+				curr := time.Now()
+				s.mx.Lock()
+				for deviceCode, code := range s.codes {
+					if code.Expiry.Before(curr) {
+						s.deleteDeviceCode(ctx, deviceCode)
+					}
+				}
+				s.mx.Unlock()
+			}
+		}
+	}()
+}
+
+func (s *MemoryDeviceCodeStore) saveToFile() error {
+	if s.savePath == "" {
+		return nil
+	}
+	f, err := os.Create(s.savePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
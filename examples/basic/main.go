@@ -14,6 +14,8 @@ import (
 	"embed"
 
 	usermemorystore "github.com/akatranlp/sentinel/account/memory_store"
+	"github.com/akatranlp/sentinel/client"
+	clientmemorystore "github.com/akatranlp/sentinel/client/memory_store"
 	"github.com/akatranlp/sentinel/openid"
 	"github.com/akatranlp/sentinel/openid/enums"
 	sessionmemorystore "github.com/akatranlp/sentinel/session/memory_store"
@@ -83,21 +85,33 @@ func main() {
 
 	tokenStore.StartSessionCleanup(ctx)
 
+	clientStore, err := clientmemorystore.NewMemoryClientStore("examples/basic/clients.json")
+	if err != nil {
+		panic(err)
+	}
+
 	sessionStore, err := sessionmemorystore.NewWithCleanupInterval(1*time.Second, "examples/basic/sessions.json")
 	if err != nil {
 		panic(err)
 	}
 
+	providers := utils.Must(InitProviders())
+
 	ip, err := openid.NewIdentityProvider(
 		basePath,
 		userStore,
 		tokenStore,
+		clientStore,
 		sessionStore,
-		openid.WithClients(openid.ClientRegistration{
-			ClientID:            "git-classrooms",
-			ClientSecret:        "",
-			TokenExchangeSecret: "supersecretsecret",
-			Scope:               enums.ScopeValues(),
+		openid.WithClients(client.Client{
+			ClientID:     "git-classrooms",
+			ClientSecret: "",
+			TokenExchangePolicy: &client.TokenExchangePolicy{
+				SubjectTokenTypes: []enums.OauthTokenType{enums.OauthTokenTypeAccessToken},
+				Providers:         providerSlugs(providers),
+				Impersonation:     true,
+			},
+			Scope: enums.ScopeValues(),
 			PostLogoutRedirectURIs: []string{
 				"http://localhost/callback",
 				"http://localhost/",
@@ -109,7 +123,7 @@ func main() {
 				"https://oidcdebugger.com/debug",
 			},
 		}),
-		openid.WithProviders(utils.Must(InitProviders())...),
+		openid.WithProviders(providers...),
 		openid.WithSessionName("SE_EXB_SESSION_ID"),
 		openid.WithSessionUnAuthedLifeTime(10*time.Hour),
 		openid.WithSessionAuthedLifeTime(30*24*time.Hour),
@@ -122,6 +136,10 @@ func main() {
 
 	f.Close()
 
+	if err = ip.StartAccountRefresh(ctx); err != nil {
+		panic(err)
+	}
+
 	log.Printf("Listening on port %d on %s\n", port, basePath)
 	ip.StartServer(ctx, fmt.Sprintf(":%d", port))
 }
@@ -9,14 +9,16 @@ import (
 )
 
 type ProviderConfig struct {
-	Type         string `json:"type"`
-	Name         string `json:"name"`
-	Slug         string `json:"slug"`
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	IconURL      string `json:"icon_url"`
-	BaseURL      string `json:"base_url"`
-	Enabled      bool   `json:"enabled"`
+	Type         string   `json:"type"`
+	Name         string   `json:"name"`
+	Slug         string   `json:"slug"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	IconURL      string   `json:"icon_url"`
+	BaseURL      string   `json:"base_url"`
+	ACR          string   `json:"acr"`
+	AMR          []string `json:"amr"`
+	Enabled      bool     `json:"enabled"`
 }
 
 func InitProviders() ([]provider.Provider, error) {
@@ -44,6 +46,8 @@ func InitProviders() ([]provider.Provider, error) {
 			ClientID:     cfg.ClientID,
 			ClientSecret: cfg.ClientSecret,
 			Type:         cfg.Type,
+			ACR:          cfg.ACR,
+			AMR:          cfg.AMR,
 		})
 		if err != nil {
 			fmt.Printf("error while initializing provider %s of type %s. Error: %v\n", cfg.Name, cfg.Type, err)
@@ -54,3 +58,11 @@ func InitProviders() ([]provider.Provider, error) {
 
 	return providers, nil
 }
+
+func providerSlugs(providers []provider.Provider) []string {
+	slugs := make([]string, 0, len(providers))
+	for _, p := range providers {
+		slugs = append(slugs, p.GetSlug())
+	}
+	return slugs
+}
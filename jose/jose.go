@@ -3,10 +3,12 @@ package jose
 import (
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"io"
 	"net/url"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v3/jwa"
 	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
 	"github.com/lestrrat-go/jwx/v3/jwt"
 	"github.com/lestrrat-go/jwx/v3/jwt/openid"
 )
@@ -23,6 +26,7 @@ import (
 var (
 	ErrInvalidOrigin   = errors.New("invalid origin provided")
 	ErrInvalidBasePath = errors.New("invalid basePath provided")
+	ErrNoAccessToken   = errors.New("the token is no access token")
 )
 
 type joseConfig struct {
@@ -31,8 +35,12 @@ type joseConfig struct {
 	accessTokenExp  time.Duration
 	refreshTokenExp time.Duration
 
+	accessTokenFormat enums.AccessTokenFormat
+
 	signingKey jwk.Key
 	publicKey  jwk.Key
+
+	pairwiseSalt []byte
 }
 
 type OptionFn func(*joseConfig) error
@@ -75,6 +83,18 @@ func WithRefeshTokenExpiration(duration time.Duration) OptionFn {
 	}
 }
 
+// WithAccessTokenFormat sets the format of the access tokens.
+// The legacy format marks access tokens with the tt claim instead of the at+jwt type of RFC 9068.
+func WithAccessTokenFormat(format enums.AccessTokenFormat) OptionFn {
+	return func(jc *joseConfig) error {
+		if !format.IsValid() {
+			return enums.ErrInvalidAccessTokenFormat
+		}
+		jc.accessTokenFormat = format
+		return nil
+	}
+}
+
 func WithSigningKey(signingKey jwk.Key) OptionFn {
 	return func(jc *joseConfig) error {
 		jc.signingKey = signingKey
@@ -97,6 +117,15 @@ func WithSigningKeyReader(signingKeyReader io.Reader) OptionFn {
 	}
 }
 
+// WithPairwiseSalt sets the secret salt of the pairwise subject identifiers.
+// It is required for pairwise subject identifiers and has to stay the same, otherwise the identifiers change.
+func WithPairwiseSalt(salt []byte) OptionFn {
+	return func(jc *joseConfig) error {
+		jc.pairwiseSalt = salt
+		return nil
+	}
+}
+
 type jose struct {
 	joseConfig
 }
@@ -142,6 +171,8 @@ var defaultConf = joseConfig{
 	basePath:        "",
 	accessTokenExp:  15 * time.Minute,
 	refreshTokenExp: 7 * 24 * time.Hour,
+
+	accessTokenFormat: enums.AccessTokenFormatLegacy,
 }
 
 func NewJoseBuilder(opts ...OptionFn) (*JoseBuilder, error) {
@@ -161,6 +192,7 @@ func NewJoseBuilder(opts ...OptionFn) (*JoseBuilder, error) {
 	if err != nil {
 		return nil, err
 	}
+
 	return &JoseBuilder{
 		joseConfig: conf,
 	}, nil
@@ -174,6 +206,19 @@ func (j *jose) Issuer() string {
 	return j.origin + j.basePath
 }
 
+// PairwiseSubject calculates the subject identifier of the user for the sector identifier of a client,
+// so clients of different sectors can not correlate their users
+func (j *jose) PairwiseSubject(sectorIdentifier, localSubject string) string {
+	hash := sha256.New()
+	// The fields are prefixed with their length, so different pairs of sector and subject can not be concatenated to the same input
+	for _, field := range []string{sectorIdentifier, localSubject} {
+		hash.Write(binary.BigEndian.AppendUint64(nil, uint64(len(field))))
+		hash.Write([]byte(field))
+	}
+	hash.Write(j.pairwiseSalt)
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
+}
+
 func (j *jose) createBaseToken(
 	sub string,
 	aud []string,
@@ -209,14 +254,93 @@ type TokenCreateArg struct {
 	Audience  []string
 	SessionID string
 	Scope     enums.Scopes
+	ClientID  string
+	// Resources restrict the audience of the access token to these resource servers
+	Resources []string
+	// ResourceScope restricts the scope of the access token to the scopes its resources accept, if it is set
+	ResourceScope enums.Scopes
+	AuthTime      time.Time
+	ACR           string
+	AMR           []string
+	// Actor is the act claim of delegated access tokens (RFC 8693 section 4.1)
+	Actor map[string]any
+	// Confirmation binds the access token to a key of the client
+	Confirmation *types.Confirmation
+	// BindRefreshToken binds the refresh token to the same key as the access token
+	BindRefreshToken bool
 }
 
+const accessTokenType = "at+jwt"
+
 func (j *jose) CreateAccessToken(arg TokenCreateArg) (token jwt.Token, signedToken string, err error) {
+	if j.accessTokenFormat == enums.AccessTokenFormatLegacy {
+		return j.createLegacyAccessToken(arg)
+	}
+
+	audience := arg.Resources
+	if len(audience) == 0 {
+		audience = arg.Audience
+	}
+
+	builder := j.createBaseToken(
+		arg.Subject,
+		audience,
+		arg.SessionID,
+		arg.accessTokenScope(),
+		arg.CurrTime,
+		j.accessTokenExp,
+	).
+		Claim(enums.ClaimClientId.String(), arg.ClientID)
+	if !arg.AuthTime.IsZero() {
+		builder.Claim(enums.ClaimAuthTime.String(), arg.AuthTime.Unix())
+	}
+	if arg.ACR != "" {
+		builder.Claim(enums.ClaimAcr.String(), arg.ACR)
+	}
+	if len(arg.AMR) > 0 {
+		builder.Claim(enums.ClaimAmr.String(), arg.AMR)
+	}
+	if arg.Actor != nil {
+		builder.Claim(enums.ClaimAct.String(), arg.Actor)
+	}
+	if arg.Confirmation != nil {
+		builder.Claim(enums.ClaimCnf.String(), arg.Confirmation)
+	}
+
+	token, err = builder.Build()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The type tells resource servers apart from other tokens of the issuer (RFC 9068 2.1)
+	headers := jws.NewHeaders()
+	if err = headers.Set(jws.TypeKey, accessTokenType); err != nil {
+		return nil, "", err
+	}
+
+	tokenBytes, err := jwt.Sign(token, jwt.WithKey(jwa.RS256(), j.signingKey, jws.WithProtectedHeaders(headers)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, string(tokenBytes), nil
+}
+
+// accessTokenScope returns the scope of the access token, which can be restricted to its resources
+func (arg TokenCreateArg) accessTokenScope() enums.Scopes {
+	if len(arg.ResourceScope) > 0 {
+		return arg.ResourceScope
+	}
+	return arg.Scope
+}
+
+func (j *jose) createLegacyAccessToken(arg TokenCreateArg) (token jwt.Token, signedToken string, err error) {
+	// The audience stays the client, which the legacy format has no other claim for
 	token, err = j.createBaseToken(
 		arg.Subject,
-		arg.Audience,
+		append(slices.Clone(arg.Audience), arg.Resources...),
 		arg.SessionID,
-		arg.Scope,
+		arg.accessTokenScope(),
 		arg.CurrTime,
 		j.accessTokenExp,
 	).
@@ -226,6 +350,17 @@ func (j *jose) CreateAccessToken(arg TokenCreateArg) (token jwt.Token, signedTok
 		return nil, "", err
 	}
 
+	if arg.Actor != nil {
+		if err = token.Set(enums.ClaimAct.String(), arg.Actor); err != nil {
+			return nil, "", err
+		}
+	}
+	if arg.Confirmation != nil {
+		if err = token.Set(enums.ClaimCnf.String(), arg.Confirmation); err != nil {
+			return nil, "", err
+		}
+	}
+
 	signedToken, err = j.signToken(token, j.signingKey)
 	if err != nil {
 		return nil, "", err
@@ -234,6 +369,47 @@ func (j *jose) CreateAccessToken(arg TokenCreateArg) (token jwt.Token, signedTok
 	return token, signedToken, nil
 }
 
+// ParseAccessToken parses and validates an access token of the issuer.
+// Both formats are accepted, so tokens issued before the format was changed stay valid.
+func (j *jose) ParseAccessToken(signedToken string, options ...jwt.ParseOption) (jwt.Token, error) {
+	msg, err := jws.ParseString(signedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.ParseString(
+		signedToken,
+		append([]jwt.ParseOption{jwt.WithKeySet(j.PublicKeys()), jwt.WithIssuer(j.Issuer())}, options...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ, ok := msg.Signatures()[0].ProtectedHeaders().Type(); ok && typ == accessTokenType {
+		return token, nil
+	}
+
+	var tokenType string
+	token.Get(enums.ClaimTokenType.String(), &tokenType)
+	if tokenType != enums.OauthTokenTypeAccessToken.String() {
+		return nil, ErrNoAccessToken
+	}
+	return token, nil
+}
+
+// AccessTokenClientID returns the client the access token was issued to.
+// Legacy access tokens have no client_id claim, their audience is the client.
+func AccessTokenClientID(token jwt.Token) string {
+	var clientID string
+	if err := token.Get(enums.ClaimClientId.String(), &clientID); err == nil {
+		return clientID
+	}
+	if aud, ok := token.Audience(); ok && len(aud) > 0 {
+		return aud[0]
+	}
+	return ""
+}
+
 func (j *jose) CreateRefreshToken(arg TokenCreateArg) (token jwt.Token, signedToken string, err error) {
 	token, err = j.createBaseToken(
 		arg.Subject,
@@ -249,6 +425,12 @@ func (j *jose) CreateRefreshToken(arg TokenCreateArg) (token jwt.Token, signedTo
 		return nil, "", err
 	}
 
+	if arg.BindRefreshToken && arg.Confirmation != nil {
+		if err = token.Set(enums.ClaimCnf.String(), arg.Confirmation); err != nil {
+			return nil, "", err
+		}
+	}
+
 	signedToken, err = j.signToken(token, j.signingKey)
 	if err != nil {
 		return nil, "", err
@@ -261,19 +443,15 @@ type IDTokenCreateArg struct {
 	TokenCreateArg
 	SignedAccessToken string
 	Nonce             string
-	AuthTime          time.Time
-	Email             string
-	EmailVerified     bool
-	Name              string
-	Username          string
-	Picture           string
+	// Claims are the released claims of the user
+	Claims map[string]any
 }
 
 func (j *jose) CreateIDToken(arg IDTokenCreateArg) (token openid.Token, signedToken string, err error) {
 	atSha := sha256.Sum256([]byte(arg.SignedAccessToken))
 	atHash := base64.RawURLEncoding.EncodeToString(atSha[:16])
 
-	token, err = openid.NewBuilder().
+	builder := openid.NewBuilder().
 		Subject(arg.Subject).
 		Issuer(j.Issuer()).
 		IssuedAt(arg.CurrTime).
@@ -286,15 +464,18 @@ func (j *jose) CreateIDToken(arg IDTokenCreateArg) (token openid.Token, signedTo
 		Claim(enums.ClaimAtHash.String(), atHash).
 		Claim(enums.ClaimNonce.String(), arg.Nonce).
 		Claim(enums.ClaimAuthTime.String(), arg.AuthTime.Unix()).
-		Email(arg.Email).
-		EmailVerified(arg.EmailVerified).
-		Name(arg.Name).
-		Nickname(arg.Username).
-		PreferredUsername(arg.Username).
-		Profile(j.Issuer()+"/user").
-		Picture(arg.Picture).
-		Claim(enums.ClaimTokenType.String(), enums.OauthTokenTypeIdToken.String()).
-		Build()
+		Claim(enums.ClaimTokenType.String(), enums.OauthTokenTypeIdToken.String())
+	if arg.ACR != "" {
+		builder.Claim(enums.ClaimAcr.String(), arg.ACR)
+	}
+	if len(arg.AMR) > 0 {
+		builder.Claim(enums.ClaimAmr.String(), arg.AMR)
+	}
+	for name, value := range arg.Claims {
+		builder.Claim(name, value)
+	}
+
+	token, err = builder.Build()
 	if err != nil {
 		return nil, "", err
 	}
@@ -307,6 +488,49 @@ func (j *jose) CreateIDToken(arg IDTokenCreateArg) (token openid.Token, signedTo
 	return token, signedToken, nil
 }
 
+const (
+	BackchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+	logoutTokenType        = "logout+jwt"
+	logoutTokenExp         = 2 * time.Minute
+)
+
+type LogoutTokenCreateArg struct {
+	CurrTime  time.Time
+	Subject   string
+	Audience  []string
+	SessionID string
+}
+
+// CreateLogoutToken creates the logout token which is sent to the back-channel logout uri of a client
+func (j *jose) CreateLogoutToken(arg LogoutTokenCreateArg) (token jwt.Token, signedToken string, err error) {
+	token, err = jwt.NewBuilder().
+		Subject(arg.Subject).
+		Issuer(j.Issuer()).
+		IssuedAt(arg.CurrTime).
+		Audience(arg.Audience).
+		Claim(enums.ClaimSid.String(), arg.SessionID).
+		Expiration(arg.CurrTime.Add(logoutTokenExp)).
+		JwtID(uuid.NewString()).
+		Claim(enums.ClaimEvents.String(), map[string]any{BackchannelLogoutEvent: map[string]any{}}).
+		Build()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The explicit type prevents that the logout token can be used as an id token
+	headers := jws.NewHeaders()
+	if err = headers.Set(jws.TypeKey, logoutTokenType); err != nil {
+		return nil, "", err
+	}
+
+	tokenBytes, err := jwt.Sign(token, jwt.WithKey(jwa.RS256(), j.signingKey, jws.WithProtectedHeaders(headers)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, string(tokenBytes), nil
+}
+
 type Tokens struct {
 	AccessToken        jwt.Token
 	RefreshToken       jwt.Token
@@ -0,0 +1,232 @@
+package openid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/akatranlp/sentinel/account"
+	"golang.org/x/oauth2"
+)
+
+// upstreamExpiryLeeway refreshes upstream tokens shortly before they expire,
+// so callers do not get a token which expires while they use it
+const upstreamExpiryLeeway = time.Minute
+
+// maxAccountRefreshBackoff limits the time between the retries of a failing refresh
+const maxAccountRefreshBackoff = time.Hour
+
+var (
+	ErrAccountRelinkRequired      = errors.New("the token of the provider can not be refreshed anymore, the provider has to be linked again")
+	ErrAccountRefreshNotSupported = errors.New("the user store can not find the expiring accounts")
+)
+
+// AccountRefreshError is reported, when the upstream token of the account could not be refreshed in the background
+type AccountRefreshError struct {
+	AccountID account.AccountID
+	Err       error
+}
+
+func (e *AccountRefreshError) Error() string {
+	return fmt.Sprintf("refresh of the %s account %s failed: %s", e.AccountID.Provider, e.AccountID.ProviderID, e.Err)
+}
+
+func (e *AccountRefreshError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorFunc receives the errors of the background work, which can not be returned to a caller
+type ErrorFunc func(ctx context.Context, err error)
+
+func (ip *IdentitiyProvider) reportError(ctx context.Context, err error) {
+	if ip.errorHandler == nil {
+		log.Println(err)
+		return
+	}
+	ip.errorHandler(ctx, err)
+}
+
+type accountBackoff struct {
+	failures int
+	next     time.Time
+}
+
+// StartAccountRefresh starts the worker, which refreshes the upstream tokens of the accounts in the background before they expire.
+// It stops when the context is cancelled.
+func (ip *IdentitiyProvider) StartAccountRefresh(ctx context.Context) error {
+	getter, ok := ip.userStore.(account.ExpiringAccountGetter)
+	if !ok || !getter.SupportsExpiringAccounts() {
+		return ErrAccountRefreshNotSupported
+	}
+
+	go func() {
+		ticker := time.NewTicker(ip.accountRefreshInterval)
+		defer ticker.Stop()
+
+		backoffs := make(map[account.AccountID]accountBackoff)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ip.refreshExpiringAccounts(ctx, getter, backoffs)
+			}
+		}
+	}()
+	return nil
+}
+
+// refreshExpiringAccounts refreshes the accounts which expire within the refresh window with bounded concurrency.
+// Failed refreshes are retried with an exponential backoff.
+func (ip *IdentitiyProvider) refreshExpiringAccounts(ctx context.Context, getter account.ExpiringAccountGetter, backoffs map[account.AccountID]accountBackoff) {
+	now := time.Now()
+	accounts, err := getter.GetAccountsExpiringBefore(ctx, now.Add(ip.accountRefreshWindow))
+	if err != nil {
+		ip.reportError(ctx, fmt.Errorf("could not get the expiring accounts: %w", err))
+		return
+	}
+
+	// Accounts which are not expiring anymore were refreshed somewhere else
+	for accountID := range backoffs {
+		if !slices.ContainsFunc(accounts, func(acc account.Account) bool { return acc.AccountID == accountID }) {
+			delete(backoffs, accountID)
+		}
+	}
+
+	var mx sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, ip.accountRefreshConcurrency)
+	defer wg.Wait()
+
+	for _, acc := range accounts {
+		mx.Lock()
+		backoff, ok := backoffs[acc.AccountID]
+		mx.Unlock()
+		if ok && now.Before(backoff.next) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := ip.refreshAccount(ctx, acc)
+
+			mx.Lock()
+			defer mx.Unlock()
+			// Accounts which have to be linked again are marked, so they are not returned anymore
+			if err == nil || errors.Is(err, ErrAccountRelinkRequired) {
+				delete(backoffs, acc.AccountID)
+				return
+			}
+
+			ip.reportError(ctx, &AccountRefreshError{AccountID: acc.AccountID, Err: err})
+			backoff := backoffs[acc.AccountID]
+			backoff.failures++
+			backoff.next = time.Now().Add(min(ip.accountRefreshInterval<<min(backoff.failures, 10), maxAccountRefreshBackoff))
+			backoffs[acc.AccountID] = backoff
+		}()
+	}
+}
+
+// accountExpiring reports if the upstream token of the account has to be refreshed before it is handed out.
+// Tokens without expiry, like the ones of GitHub OAuth apps, never expire.
+func accountExpiring(acc account.Account) bool {
+	return !acc.Expiry.IsZero() && time.Until(acc.Expiry) < upstreamExpiryLeeway
+}
+
+// refreshAccount refreshes the upstream token of the account and stores the result.
+// Concurrent refreshes of the same account are coalesced, because the providers rotate the refresh tokens.
+func (ip *IdentitiyProvider) refreshAccount(ctx context.Context, acc account.Account) (account.Account, error) {
+	key := acc.Provider + "/" + acc.ProviderID
+	v, err, _ := ip.accountRefreshes.Do(key, func() (any, error) {
+		// The refresh is shared, so it must not fail because the first caller went away
+		ctx := context.WithoutCancel(ctx)
+
+		// The account could have been refreshed or linked again since the caller read it,
+		// then its refresh token is already rotated
+		if current, err := ip.userStore.GetAccountByProvider(ctx, acc.UserID, acc.Provider); err == nil {
+			if current.RefreshToken != acc.RefreshToken && !current.RelinkRequired {
+				return current, nil
+			}
+			acc = current
+		}
+
+		p, ok := ip.providers[acc.Provider]
+		if !ok {
+			return nil, fmt.Errorf("the provider %s is not registered", acc.Provider)
+		}
+
+		if acc.RelinkRequired {
+			return nil, ErrAccountRelinkRequired
+		}
+		if acc.RefreshToken == "" || !acc.RefreshExpiry.IsZero() && acc.RefreshExpiry.Before(time.Now()) {
+			return nil, ip.markAccountRelinkRequired(ctx, acc)
+		}
+
+		token, err := p.RefreshToken(ctx, &oauth2.Token{
+			AccessToken:  acc.AccessToken,
+			RefreshToken: acc.RefreshToken,
+			Expiry:       acc.Expiry,
+			TokenType:    acc.TokenType,
+		})
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return nil, ip.markAccountRelinkRequired(ctx, acc)
+		} else if err != nil {
+			return nil, err
+		}
+
+		var refreshExpiry time.Time
+		if refreshExpiresIn, ok := token.Extra("refresh_expires_in").(int64); ok {
+			refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
+		}
+		if refreshExpiresIn, ok := token.Extra("refresh_token_expires_in").(int64); ok {
+			refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
+		}
+
+		var idToken string
+		if idTokenStr, ok := token.Extra("id_token").(string); ok {
+			idToken = idTokenStr
+		}
+
+		acc.AccessToken = token.AccessToken
+		acc.Expiry = token.Expiry
+		acc.RefreshToken = token.RefreshToken
+		acc.RefreshExpiry = refreshExpiry
+		acc.TokenType = token.TokenType
+		acc.RelinkRequired = false
+		if idToken != "" {
+			acc.IDToken = idToken
+		}
+
+		if err := ip.userStore.UpdateAccount(ctx, acc.AccountID, acc); err != nil {
+			return nil, err
+		}
+		return acc, nil
+	})
+	if err != nil {
+		return account.Account{}, err
+	}
+	return v.(account.Account), nil
+}
+
+// markAccountRelinkRequired stores that the user has to link the provider again
+func (ip *IdentitiyProvider) markAccountRelinkRequired(ctx context.Context, acc account.Account) error {
+	acc.RelinkRequired = true
+	if err := ip.userStore.UpdateAccount(ctx, acc.AccountID, acc); err != nil {
+		return err
+	}
+	return ErrAccountRelinkRequired
+}
@@ -0,0 +1,64 @@
+package openid
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/provider"
+)
+
+// requestedACRs returns the authentication context classes the client asked for.
+// An acr requested as essential claim has to be met, the acr_values are only voluntary.
+func requestedACRs(authReq types.AuthRequest) (acrs []string, essential bool) {
+	if req, ok := authReq.Claims.IDToken[enums.ClaimAcr.String()]; ok && req.Essential {
+		for _, value := range append([]any{req.Value}, req.Values...) {
+			if acr, ok := value.(string); ok {
+				acrs = append(acrs, acr)
+			}
+		}
+		if len(acrs) > 0 {
+			return acrs, true
+		}
+	}
+	return strings.Fields(authReq.ACRValues), false
+}
+
+// acrSatisfied reports if the acr of the session is one of the requested acrs
+func acrSatisfied(acrs []string, acr string) bool {
+	return len(acrs) == 0 || slices.Contains(acrs, acr)
+}
+
+// requiresStepUp reports if the user has to login again, because the current login does not satisfy the requested acr.
+// This is only useful if one of the providers can satisfy it.
+func (ip *IdentitiyProvider) requiresStepUp(ctx context.Context, authReq types.AuthRequest) bool {
+	acrs, _ := requestedACRs(authReq)
+	return !acrSatisfied(acrs, ip.sessionManager.GetACR(ctx)) && len(ip.providersForACRs(acrs)) > 0
+}
+
+// providersForACRs returns the providers whose login satisfies one of the acrs
+func (ip *IdentitiyProvider) providersForACRs(acrs []string) map[string]provider.Provider {
+	providers := make(map[string]provider.Provider)
+	for slug, p := range ip.providers {
+		if acrSatisfied(acrs, p.GetAuthContext().ACR) {
+			providers[slug] = p
+		}
+	}
+	return providers
+}
+
+// loginProviders returns the providers offered on the login page.
+// If the pending authorization request asks for an acr, only the providers satisfying it are offered.
+func (ip *IdentitiyProvider) loginProviders(ctx context.Context) map[string]provider.Provider {
+	authReq, ok := ip.sessionManager.PeekAuthRequest(ctx)
+	if !ok {
+		return ip.providers
+	}
+	acrs, _ := requestedACRs(authReq)
+	if providers := ip.providersForACRs(acrs); len(providers) > 0 {
+		return providers
+	}
+	return ip.providers
+}
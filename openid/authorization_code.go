@@ -1,9 +1,14 @@
 package openid
 
 import (
+	"context"
+	"errors"
 	"time"
 
+	"github.com/akatranlp/sentinel/authcode"
+	"github.com/akatranlp/sentinel/openid/enums"
 	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/token"
 	"github.com/google/uuid"
 )
 
@@ -11,21 +16,133 @@ type AuthTokenValues struct {
 	types.AuthRequest
 	UserID   string
 	AuthTime time.Time
+	// ACR and AMR describe how the user authenticated
+	ACR string
+	AMR []string
+	// SessionID is created with the code, so the browser session knows the sid of the tokens
+	SessionID string
 }
 
-func (ip *IdentitiyProvider) createAuthToken(authReq AuthTokenValues) string {
+func (ip *IdentitiyProvider) createAuthToken(ctx context.Context, authReq AuthTokenValues) (string, error) {
 	code := uuid.NewString()
-	ip.authMap.Store(code, authReq)
-	time.AfterFunc(10*time.Second, func() {
-		ip.authMap.Delete(code)
+	expiry := time.Now().Add(ip.authCodeLifeTime)
+	err := ip.authCodeStore.CreateAuthCode(ctx, authcode.AuthCode{
+		Code:        code,
+		AuthRequest: authReq.AuthRequest,
+		UserID:      authReq.UserID,
+		AuthTime:    authReq.AuthTime,
+		ACR:         authReq.ACR,
+		AMR:         authReq.AMR,
+		SessionID:   authReq.SessionID,
+		Expiry:      expiry,
+		RetainUntil: expiry.Add(ip.authCodeRetention),
 	})
-	return code
+	if err != nil {
+		return "", err
+	}
+	return code, nil
 }
 
-func (ip *IdentitiyProvider) validateCode(code string) (AuthTokenValues, bool) {
-	v, ok := ip.authMap.LoadAndDelete(code)
-	if !ok {
-		return AuthTokenValues{}, false
+// redeemCode checks the token request against the authorization of the code and consumes the code, so it can only be used once.
+// A replayed code revokes the tokens, which were already issued for it (RFC 6749 4.1.2).
+func (ip *IdentitiyProvider) redeemCode(ctx context.Context, tokenReq types.TokenRequest) (AuthTokenValues, *TokenError) {
+	invalidCode := &TokenError{
+		ErrorType:        TokenErrorTypeInvalidGrant,
+		ErrorDescription: "invalid or expired code",
+	}
+
+	authCode, err := ip.authCodeStore.GetAuthCode(ctx, tokenReq.Code)
+	if errors.Is(err, authcode.ErrAuthCodeNotFound) {
+		return AuthTokenValues{}, invalidCode
+	} else if err != nil {
+		return AuthTokenValues{}, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
+	}
+	if !authCode.RedeemedAt.IsZero() {
+		ip.revokeReplayedCode(ctx, authCode)
+		return AuthTokenValues{}, invalidCode
+	}
+	if authCode.Expiry.Before(time.Now()) {
+		return AuthTokenValues{}, invalidCode
+	}
+
+	// A request, which does not match the authorization, must not burn the code of the client
+	if tokenErr := checkCodeBinding(authCode.AuthRequest, tokenReq); tokenErr != nil {
+		return AuthTokenValues{}, tokenErr
+	}
+
+	authCode, err = ip.authCodeStore.ConsumeAuthCode(ctx, tokenReq.Code)
+	if errors.Is(err, authcode.ErrAuthCodeRedeemed) {
+		// A concurrent request redeemed the code since it was read
+		ip.revokeReplayedCode(ctx, authCode)
+		return AuthTokenValues{}, invalidCode
+	} else if errors.Is(err, authcode.ErrAuthCodeNotFound) {
+		return AuthTokenValues{}, invalidCode
+	} else if err != nil {
+		return AuthTokenValues{}, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
+	}
+	return AuthTokenValues{
+		AuthRequest: authCode.AuthRequest,
+		UserID:      authCode.UserID,
+		AuthTime:    authCode.AuthTime,
+		ACR:         authCode.ACR,
+		AMR:         authCode.AMR,
+		SessionID:   authCode.SessionID,
+	}, nil
+}
+
+// revokeReplayedCode ends the session of the tokens of the code, because the code may have been stolen.
+// The code is marked as replayed first, so tokens, which are created concurrently for the code, are revoked by checkCodeReplay.
+func (ip *IdentitiyProvider) revokeReplayedCode(ctx context.Context, authCode authcode.AuthCode) {
+	description := "the session of the code was revoked"
+	if err := ip.authCodeStore.MarkAuthCodeReplayed(ctx, authCode.Code); err != nil {
+		description = "the code could not be marked as replayed: " + err.Error()
+	} else if _, err := ip.tokenStore.GetSession(ctx, authCode.SessionID); errors.Is(err, token.ErrSessionNotFound) {
+		description = "no tokens were issued for the code yet, they are revoked when they are created"
+	} else if err != nil {
+		description = "the session of the code could not be read: " + err.Error()
+	} else if err := ip.tokenStore.RevokeSession(ctx, authCode.SessionID); err != nil {
+		description = "the session of the code could not be revoked: " + err.Error()
+	}
+	ip.emitSecurityEvent(ctx, SecurityEvent{
+		Type:        enums.SecurityEventTypeAuthorizationCodeReplay,
+		ClientID:    authCode.AuthRequest.ClientID,
+		UserID:      authCode.UserID,
+		SessionID:   authCode.SessionID,
+		Description: description,
+	})
+}
+
+// checkCodeReplay revokes the session of the tokens, which were just created for the code, if the code was replayed meanwhile.
+// The replay could not find the session before it was stored, so the code is checked after the session is stored.
+func (ip *IdentitiyProvider) checkCodeReplay(ctx context.Context, code, sessionID string) *TokenError {
+	authCode, err := ip.authCodeStore.GetAuthCode(ctx, code)
+	if errors.Is(err, authcode.ErrAuthCodeNotFound) {
+		return nil
+	}
+	if err == nil && authCode.ReplayedAt.IsZero() {
+		return nil
+	}
+
+	if revokeErr := ip.tokenStore.RevokeSession(ctx, sessionID); revokeErr != nil {
+		return &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: revokeErr.Error(),
+		}
+	}
+	if err != nil {
+		return &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
+	}
+	return &TokenError{
+		ErrorType:        TokenErrorTypeInvalidGrant,
+		ErrorDescription: "invalid or expired code",
 	}
-	return v.(AuthTokenValues), true
 }
@@ -0,0 +1,273 @@
+package openid
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/akatranlp/sentinel/authcode"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/token"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+const testRedirectURI = "http://localhost/callback"
+
+// newTestCode stores an authorization code of the client with a S256 challenge of the verifier
+func newTestCode(t *testing.T, ctx context.Context, ip *IdentitiyProvider, verifier string, expiry time.Time) authcode.AuthCode {
+	t.Helper()
+	redirectURI, err := url.Parse(testRedirectURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authCode := authcode.AuthCode{
+		Code: uuid.NewString(),
+		AuthRequest: types.AuthRequest{
+			ClientID:            "client",
+			RedirectURI:         redirectURI,
+			ResponseType:        enums.ResponseTypeCode,
+			CodeChallengeMethod: enums.CodeChallengeMethodS256,
+			CodeChallenge:       oauth2.S256ChallengeFromVerifier(verifier),
+		},
+		UserID:      "user",
+		SessionID:   uuid.NewString(),
+		Expiry:      expiry,
+		RetainUntil: time.Now().Add(time.Minute),
+	}
+	if err = ip.authCodeStore.CreateAuthCode(ctx, authCode); err != nil {
+		t.Fatal(err)
+	}
+	return authCode
+}
+
+func newTestCodeRequest(code, verifier string) types.TokenRequest {
+	return types.TokenRequest{
+		ClientID:     "client",
+		GrantType:    enums.GrantTypeAuthorizationCode,
+		RedirectURI:  testRedirectURI,
+		Code:         code,
+		CodeVerifier: verifier,
+	}
+}
+
+func TestRedeemCode(t *testing.T) {
+	verifier := oauth2.GenerateVerifier()
+
+	tests := []struct {
+		name    string
+		expiry  time.Duration
+		modify  func(*types.TokenRequest)
+		wantErr TokenErrorType
+		// burned reports if the code can not be redeemed after the request
+		burned bool
+	}{
+		{
+			name:   "valid code",
+			expiry: time.Minute,
+			burned: true,
+		},
+		{
+			name:    "unknown code",
+			expiry:  time.Minute,
+			modify:  func(req *types.TokenRequest) { req.Code = uuid.NewString() },
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+		{
+			name:    "expired code",
+			expiry:  -time.Second,
+			wantErr: TokenErrorTypeInvalidGrant,
+			burned:  true,
+		},
+		{
+			name:    "other client",
+			expiry:  time.Minute,
+			modify:  func(req *types.TokenRequest) { req.ClientID = "other" },
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+		{
+			name:    "other redirect uri",
+			expiry:  time.Minute,
+			modify:  func(req *types.TokenRequest) { req.RedirectURI = "http://localhost/other" },
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+		{
+			name:    "wrong code verifier",
+			expiry:  time.Minute,
+			modify:  func(req *types.TokenRequest) { req.CodeVerifier = oauth2.GenerateVerifier() },
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+		{
+			name:    "missing code verifier",
+			expiry:  time.Minute,
+			modify:  func(req *types.TokenRequest) { req.CodeVerifier = "" },
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []SecurityEvent
+			ip := newTestProvider(t, WithSecurityEvents(func(ctx context.Context, event SecurityEvent) {
+				events = append(events, event)
+			}))
+			ctx := testContext(t, ip)
+			authCode := newTestCode(t, ctx, ip, verifier, time.Now().Add(tt.expiry))
+
+			req := newTestCodeRequest(authCode.Code, verifier)
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+
+			values, tokenErr := ip.redeemCode(ctx, req)
+			if tt.wantErr != "" {
+				if tokenErr == nil || tokenErr.ErrorType != tt.wantErr {
+					t.Fatalf("expected error %s, got %v", tt.wantErr, tokenErr)
+				}
+			} else if tokenErr != nil {
+				t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+			} else if values.UserID != authCode.UserID || values.SessionID != authCode.SessionID {
+				t.Errorf("expected the values of the code, got %+v", values)
+			}
+
+			if len(events) != 0 {
+				t.Errorf("expected no security events, got %+v", events)
+			}
+
+			// A request, which does not match the code, must not prevent the client from redeeming it
+			_, tokenErr = ip.redeemCode(ctx, newTestCodeRequest(authCode.Code, verifier))
+			if burned := tokenErr != nil; burned != tt.burned {
+				t.Errorf("expected the code to be burned %t, got %t", tt.burned, burned)
+			}
+		})
+	}
+}
+
+func TestRedeemCodeReplay(t *testing.T) {
+	verifier := oauth2.GenerateVerifier()
+
+	tests := []struct {
+		name string
+		// issued reports if tokens were issued for the code before the replay
+		issued bool
+	}{
+		{
+			name:   "replay after tokens were issued",
+			issued: true,
+		},
+		{
+			name:   "replay without issued tokens",
+			issued: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []SecurityEvent
+			ip := newTestProvider(t, WithSecurityEvents(func(ctx context.Context, event SecurityEvent) {
+				events = append(events, event)
+			}))
+			ctx := testContext(t, ip)
+			authCode := newTestCode(t, ctx, ip, verifier, time.Now().Add(time.Minute))
+
+			if _, tokenErr := ip.redeemCode(ctx, newTestCodeRequest(authCode.Code, verifier)); tokenErr != nil {
+				t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+			}
+			if tt.issued {
+				if err := ip.tokenStore.SetSession(ctx, token.Session{
+					SessionID: authCode.SessionID,
+					UserID:    authCode.UserID,
+					ClientID:  authCode.AuthRequest.ClientID,
+					Expiry:    time.Now().Add(time.Hour),
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, tokenErr := ip.redeemCode(ctx, newTestCodeRequest(authCode.Code, verifier))
+			if tokenErr == nil || tokenErr.ErrorType != TokenErrorTypeInvalidGrant {
+				t.Fatalf("expected the replayed code to be rejected, got %v", tokenErr)
+			}
+
+			if len(events) != 1 {
+				t.Fatalf("expected 1 security event, got %+v", events)
+			}
+			if events[0].Type != enums.SecurityEventTypeAuthorizationCodeReplay || events[0].SessionID != authCode.SessionID {
+				t.Errorf("expected a replay event of the session, got %+v", events[0])
+			}
+			if _, err := ip.tokenStore.GetSession(ctx, authCode.SessionID); err == nil {
+				t.Error("expected the session of the code to be revoked")
+			}
+		})
+	}
+}
+
+func TestRedeemCodeReplayDuringTokenCreation(t *testing.T) {
+	verifier := oauth2.GenerateVerifier()
+
+	tests := []struct {
+		name     string
+		replayed bool
+		wantErr  TokenErrorType
+	}{
+		{
+			name: "no replay",
+		},
+		{
+			name:     "replay before the session is stored",
+			replayed: true,
+			wantErr:  TokenErrorTypeInvalidGrant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []SecurityEvent
+			ip := newTestProvider(t, WithSecurityEvents(func(ctx context.Context, event SecurityEvent) {
+				events = append(events, event)
+			}))
+			ctx := testContext(t, ip)
+			authCode := newTestCode(t, ctx, ip, verifier, time.Now().Add(time.Minute))
+
+			values, tokenErr := ip.redeemCode(ctx, newTestCodeRequest(authCode.Code, verifier))
+			if tokenErr != nil {
+				t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+			}
+
+			// The replay arrives after the code was redeemed, but before the tokens of the first request are stored
+			if tt.replayed {
+				if _, tokenErr := ip.redeemCode(ctx, newTestCodeRequest(authCode.Code, verifier)); tokenErr == nil {
+					t.Fatal("expected the replayed code to be rejected")
+				}
+				if len(events) != 1 || events[0].Type != enums.SecurityEventTypeAuthorizationCodeReplay {
+					t.Fatalf("expected a replay event, got %+v", events)
+				}
+			}
+
+			if err := ip.tokenStore.SetSession(ctx, token.Session{
+				SessionID: values.SessionID,
+				UserID:    values.UserID,
+				ClientID:  values.ClientID,
+				Expiry:    time.Now().Add(time.Hour),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			tokenErr = ip.checkCodeReplay(ctx, authCode.Code, values.SessionID)
+			if tt.wantErr != "" {
+				if tokenErr == nil || tokenErr.ErrorType != tt.wantErr {
+					t.Fatalf("expected error %s, got %v", tt.wantErr, tokenErr)
+				}
+			} else if tokenErr != nil {
+				t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+			}
+
+			_, err := ip.tokenStore.GetSession(ctx, values.SessionID)
+			if revoked := err != nil; revoked != tt.replayed {
+				t.Errorf("expected the session to be revoked %t, got %t", tt.replayed, revoked)
+			}
+		})
+	}
+}
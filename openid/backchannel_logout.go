@@ -0,0 +1,96 @@
+package openid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/sentinel/jose"
+)
+
+// logoutSessions ends the token sessions of the browser session and notifies their clients about the logout.
+// Sessions with offline access are meant to outlive the login, so they are not revoked (Back-Channel Logout 1.0 2.7).
+func (ip *IdentitiyProvider) logoutSessions(ctx context.Context, sessionIDs []string) {
+	j := jose.GetJose(ctx)
+	currTime := time.Now()
+	for _, sessionID := range sessionIDs {
+		sess, err := ip.tokenStore.GetSession(ctx, sessionID)
+		if err != nil {
+			// The session may belong to a client which did not get a refresh token and is already gone
+			continue
+		}
+		if !sess.IsOffline() {
+			ip.tokenStore.RevokeSession(ctx, sess.SessionID)
+		}
+
+		reg, err := ip.clientStore.GetClient(ctx, sess.ClientID)
+		if err != nil || reg.BackchannelLogoutURI == "" {
+			continue
+		}
+
+		// The sid is always sent, so clients which require it are served as well
+		_, logoutToken, err := j.CreateLogoutToken(jose.LogoutTokenCreateArg{
+			CurrTime:  currTime,
+			Subject:   ip.subjectIdentifier(ctx, reg, sess.UserID),
+			Audience:  []string{sess.ClientID},
+			SessionID: sess.SessionID,
+		})
+		if err != nil {
+			ip.reportError(ctx, fmt.Errorf("could not create logout token: %w", err))
+			continue
+		}
+
+		// The user should not wait for slow clients, so the tokens are sent in the background
+		go ip.sendLogoutToken(context.WithoutCancel(ctx), reg.BackchannelLogoutURI, logoutToken)
+	}
+}
+
+// sendLogoutToken posts the logout token to the client and retries on network and server errors
+func (ip *IdentitiyProvider) sendLogoutToken(ctx context.Context, logoutURI, logoutToken string) {
+	body := url.Values{"logout_token": {logoutToken}}.Encode()
+
+	var err error
+	for attempt := range ip.backchannelLogoutRetries + 1 {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+		}
+
+		var retry bool
+		retry, err = ip.postLogoutToken(ctx, logoutURI, body)
+		if err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		ip.reportError(ctx, fmt.Errorf("back-channel logout to %s failed: %w", logoutURI, err))
+	}
+}
+
+func (ip *IdentitiyProvider) postLogoutToken(ctx context.Context, logoutURI, body string) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, ip.backchannelLogoutTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, logoutURI, strings.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := ip.logoutHTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode >= 500:
+		return true, fmt.Errorf("client responded with %s", res.Status)
+	default:
+		return false, fmt.Errorf("client rejected the logout token with %s", res.Status)
+	}
+}
@@ -0,0 +1,26 @@
+package openid
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"github.com/akatranlp/sentinel/openid/web"
+)
+
+// OauthCheckSession serves the check_session_iframe of the OpenID Connect Session Management.
+// The clients embed it and ask it via postMessage if the browser state changed.
+func (ip *IdentitiyProvider) OauthCheckSession(w http.ResponseWriter, r *http.Request) {
+	web.CheckSession(ip.sessionManager.BrowserStateCookieName()).Render(r.Context(), w)
+}
+
+// sessionState calculates the session_state of the authentication response,
+// which the check session iframe compares against the current browser state
+func sessionState(clientID string, redirectURI url.URL, browserState string) string {
+	salt := rand.Text()
+	origin := redirectURI.Scheme + "://" + redirectURI.Host
+	hash := sha256.Sum256([]byte(clientID + " " + origin + " " + browserState + " " + salt))
+	return hex.EncodeToString(hash[:]) + "." + salt
+}
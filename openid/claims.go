@@ -0,0 +1,102 @@
+package openid
+
+import (
+	"context"
+	"slices"
+
+	"github.com/akatranlp/sentinel/account"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+)
+
+// ClaimValuesFunc returns the values of the custom claims of the user
+type ClaimValuesFunc func(ctx context.Context, user account.User) (map[string]any, error)
+
+// defaultScopeClaims is the release policy of the standard scopes (OpenID Connect Core 5.4)
+var defaultScopeClaims = map[enums.Scope][]string{
+	enums.ScopeProfile: {
+		enums.ClaimName.String(),
+		enums.ClaimNickname.String(),
+		enums.ClaimPreferredUsername.String(),
+		enums.ClaimProfile.String(),
+		enums.ClaimPicture.String(),
+	},
+	enums.ScopeEmail: {
+		enums.ClaimEmail.String(),
+		enums.ClaimEmailVerified.String(),
+	},
+}
+
+// supportsScopes reports if the scopes are standard scopes or custom scopes of the release policy
+func (ip *IdentitiyProvider) supportsScopes(scopes enums.Scopes) bool {
+	for _, scope := range scopes {
+		if _, ok := ip.scopeClaims[scope]; !ok && !scope.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+// releasedClaims returns the claims of the granted scopes and the claims requested by the claims parameter
+func (ip *IdentitiyProvider) releasedClaims(scopes enums.Scopes, requests map[string]types.ClaimRequest) []string {
+	var claims []string
+	for _, scope := range scopes {
+		for _, claim := range ip.scopeClaims[scope] {
+			if !slices.Contains(claims, claim) {
+				claims = append(claims, claim)
+			}
+		}
+	}
+	for claim := range requests {
+		if !slices.Contains(claims, claim) {
+			claims = append(claims, claim)
+		}
+	}
+	return claims
+}
+
+// userClaims returns the values of the claims the user has.
+// Claims requested with a value or values are only returned if the value of the user matches.
+func (ip *IdentitiyProvider) userClaims(ctx context.Context, issuer string, user account.User, claims []string, requests map[string]types.ClaimRequest) (map[string]any, error) {
+	var customValues map[string]any
+	values := make(map[string]any)
+	for _, claim := range claims {
+		value, ok := userClaimValue(issuer, user, claim)
+		// The registered claims are set by the provider, so custom values can not override them
+		if !ok && !enums.Claim(claim).IsValid() && ip.claimValues != nil {
+			if customValues == nil {
+				var err error
+				if customValues, err = ip.claimValues(ctx, user); err != nil {
+					return nil, err
+				}
+			}
+			value, ok = customValues[claim]
+		}
+		if !ok || !requests[claim].Matches(value) {
+			continue
+		}
+		values[claim] = value
+	}
+	return values, nil
+}
+
+func userClaimValue(issuer string, user account.User, claim string) (any, bool) {
+	var value any
+	switch enums.Claim(claim) {
+	case enums.ClaimName:
+		value = user.Name
+	case enums.ClaimNickname, enums.ClaimPreferredUsername:
+		value = user.Username
+	case enums.ClaimProfile:
+		value = issuer + "/user"
+	case enums.ClaimPicture:
+		value = user.Picture
+	case enums.ClaimEmail:
+		value = user.Email
+	case enums.ClaimEmailVerified:
+		return user.EmailVerified, true
+	default:
+		return nil, false
+	}
+	return value, value != ""
+}
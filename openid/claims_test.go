@@ -0,0 +1,33 @@
+package openid
+
+import (
+	"testing"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+func TestSupportsScopes(t *testing.T) {
+	ip := newTestProvider(t, WithScopeClaims("custom", "custom_claim"))
+	other := newTestProvider(t)
+
+	tests := []struct {
+		name   string
+		ip     *IdentitiyProvider
+		scopes enums.Scopes
+		want   bool
+	}{
+		{name: "standard scopes", ip: ip, scopes: enums.Scopes{enums.ScopeOpenid, enums.ScopeProfile}, want: true},
+		{name: "custom scope", ip: ip, scopes: enums.Scopes{enums.ScopeOpenid, "custom"}, want: true},
+		{name: "unknown scope", ip: ip, scopes: enums.Scopes{enums.ScopeOpenid, "unknown"}},
+		// The custom scopes belong to the identity provider, which released their claims
+		{name: "custom scope of another provider", ip: other, scopes: enums.Scopes{"custom"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ip.supportsScopes(tt.scopes); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
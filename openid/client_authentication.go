@@ -0,0 +1,298 @@
+package openid
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/lestrrat-go/httprc/v3"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+)
+
+const (
+	clientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	clientKeySetRefreshInterval  = 5 * time.Minute
+	// clientAssertionMaxLifeTime bounds how long an assertion is valid, so the used ones are not remembered for long
+	clientAssertionMaxLifeTime = 5 * time.Minute
+)
+
+// authenticateClient authenticates the client of a back-channel request with one of the enabled methods.
+// The form has to be parsed before and gets the client_id and client_secret set, so it can be decoded afterwards.
+// The returned method is the one, which was verified in this request.
+func (ip *IdentitiyProvider) authenticateClient(r *http.Request) (client.Client, enums.EndpointAuthMethod, *TokenError) {
+	var reg client.Client
+	var method enums.EndpointAuthMethod
+	var tokenErr *TokenError
+	if r.Form.Has(ClientAuthFormValueClientAssertion.String()) {
+		reg, method, tokenErr = ip.authenticateClientAssertion(r)
+	} else if certReg, err := ip.clientStore.GetClient(r.Context(), r.FormValue(ClientAuthFormValueClientId.String())); err == nil && isTLSClientAuthMethod(certReg.TokenEndpointAuthMethod) {
+		reg, method, tokenErr = ip.authenticateClientCertificate(r, certReg)
+	} else {
+		reg, method, tokenErr = ip.authenticateClientSecret(r)
+	}
+	if tokenErr != nil {
+		return client.Client{}, "", tokenErr
+	}
+
+	if !slices.Contains(ip.clientAuthMethods, method) {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: fmt.Sprintf("client authentication method %s is not enabled", method),
+		}
+	}
+	if reg.TokenEndpointAuthMethod != "" && reg.TokenEndpointAuthMethod != method {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: fmt.Sprintf("client has to authenticate with %s", reg.TokenEndpointAuthMethod),
+		}
+	}
+
+	r.Form.Set(ClientAuthFormValueClientId.String(), reg.ClientID)
+	return reg, method, nil
+}
+
+// Some oauth clients are sending the client id and secret as basic auth and some as form-values, we allow both
+func (ip *IdentitiyProvider) authenticateClientSecret(r *http.Request) (client.Client, enums.EndpointAuthMethod, *TokenError) {
+	method := enums.EndpointAuthMethodClientSecretBasic
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		method = enums.EndpointAuthMethodClientSecretPost
+		clientID = r.FormValue(ClientAuthFormValueClientId.String())
+		clientSecret = r.FormValue(ClientAuthFormValueClientSecret.String())
+	}
+	r.Form.Set(ClientAuthFormValueClientSecret.String(), clientSecret)
+
+	reg, err := ip.clientStore.GetClient(r.Context(), clientID)
+	if err != nil {
+		return client.Client{}, "", &TokenError{
+			ErrorType: TokenErrorTypeInvalidClient,
+		}
+	}
+
+	// Public clients have no credentials to authenticate with,
+	// clients with keys or certificates have to use their assertion or mutual-TLS method instead
+	if reg.ClientSecret == "" {
+		if !reg.IsPublic() {
+			return client.Client{}, "", &TokenError{
+				ErrorType:        TokenErrorTypeInvalidClient,
+				ErrorDescription: "client has to authenticate with its registered credentials",
+			}
+		}
+		return reg, enums.EndpointAuthMethodNone, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(clientSecret), []byte(reg.ClientSecret)) != 1 {
+		return client.Client{}, "", &TokenError{
+			ErrorType: TokenErrorTypeInvalidClient,
+		}
+	}
+
+	return reg, method, nil
+}
+
+// authenticateClientAssertion verifies the client assertion of RFC 7523.
+// HMAC signed assertions are verified with the client secret, all other ones with the keys of the client.
+func (ip *IdentitiyProvider) authenticateClientAssertion(r *http.Request) (client.Client, enums.EndpointAuthMethod, *TokenError) {
+	if r.FormValue(ClientAuthFormValueClientAssertionType.String()) != clientAssertionTypeJWTBearer {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: "unsupported client_assertion_type",
+		}
+	}
+
+	assertion := r.FormValue(ClientAuthFormValueClientAssertion.String())
+	unverifiedToken, err := jwt.ParseInsecure([]byte(assertion))
+	if err != nil {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	clientID, _ := unverifiedToken.Subject()
+	if formClientID := r.FormValue(ClientAuthFormValueClientId.String()); formClientID != "" && formClientID != clientID {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: "client_id does not match the subject of the client assertion",
+		}
+	}
+
+	reg, err := ip.clientStore.GetClient(r.Context(), clientID)
+	if err != nil {
+		return client.Client{}, "", &TokenError{
+			ErrorType: TokenErrorTypeInvalidClient,
+		}
+	}
+
+	msg, err := jws.ParseString(assertion)
+	if err != nil || len(msg.Signatures()) != 1 {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: "invalid client assertion",
+		}
+	}
+	alg, _ := msg.Signatures()[0].ProtectedHeaders().Algorithm()
+
+	ctx := r.Context()
+	var method enums.EndpointAuthMethod
+	var keyOption jwt.ParseOption
+	if strings.HasPrefix(alg.String(), "HS") {
+		method = enums.EndpointAuthMethodClientSecretJwt
+		if reg.ClientSecret == "" {
+			return client.Client{}, "", &TokenError{
+				ErrorType:        TokenErrorTypeInvalidClient,
+				ErrorDescription: "client has no secret to verify the client assertion",
+			}
+		}
+		keyOption = jwt.WithKey(alg, []byte(reg.ClientSecret))
+	} else {
+		method = enums.EndpointAuthMethodPrivateKeyJwt
+		set, err := ip.clientKeySet(ctx, reg)
+		if err != nil {
+			return client.Client{}, "", &TokenError{
+				ErrorType:        TokenErrorTypeInvalidClient,
+				ErrorDescription: err.Error(),
+			}
+		}
+		keyOption = jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true), jws.WithRequireKid(false))
+	}
+
+	token, err := jwt.ParseString(
+		assertion,
+		keyOption,
+		jwt.WithIssuer(reg.ClientID),
+		jwt.WithSubject(reg.ClientID),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+		jwt.WithRequiredClaim(jwt.JwtIDKey),
+	)
+	if err != nil {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	// The audience has to be the endpoint that is called, but the issuer is accepted too
+	j := jose.GetJose(ctx)
+	aud, _ := token.Audience()
+	if !slices.Contains(aud, j.Issuer()) && !slices.Contains(aud, ip.requestURL(r)) {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: "invalid audience of the client assertion",
+		}
+	}
+
+	jti, _ := token.JwtID()
+	exp, _ := token.Expiration()
+	if time.Until(exp) > clientAssertionMaxLifeTime {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: "client assertion expires too late",
+		}
+	}
+	if !ip.replayCache.Add(reg.ClientID+":"+jti, exp) {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: "client assertion was already used",
+		}
+	}
+
+	return reg, method, nil
+}
+
+// clientKeySetCache fetches the keys of jwks uris and refreshes them in the background.
+// The cache is started with the first jwks uri, so it does not run for providers without such clients.
+type clientKeySetCache struct {
+	client *http.Client
+	once   sync.Once
+	cache  *jwk.Cache
+	err    error
+}
+
+func (c *clientKeySetCache) get() (*jwk.Cache, error) {
+	c.once.Do(func() {
+		c.cache, c.err = jwk.NewCache(context.Background(), httprc.NewClient(httprc.WithHTTPClient(c.client)))
+	})
+	return c.cache, c.err
+}
+
+// clientKeySet returns the registered keys of the client.
+// Keys of a jwks uri are cached and refreshed, so key rotations of the client are picked up.
+func (ip *IdentitiyProvider) clientKeySet(ctx context.Context, reg client.Client) (jwk.Set, error) {
+	if reg.JWKS != nil {
+		return reg.JWKS, nil
+	}
+	if reg.JWKSURI == "" {
+		return nil, errors.New("the client has no keys registered")
+	}
+
+	cache, err := ip.clientKeySets.get()
+	if err != nil {
+		return nil, err
+	}
+	if !cache.IsRegistered(ctx, reg.JWKSURI) {
+		// The cache only reports a failed first fetch after the timeout, so the keys are fetched once before
+		if _, err = jwk.Fetch(ctx, reg.JWKSURI, jwk.WithHTTPClient(ip.outboundHTTPClient)); err != nil {
+			return nil, err
+		}
+		fetchCtx, cancel := context.WithTimeout(ctx, outboundRequestTimeout)
+		defer cancel()
+		err = cache.Register(fetchCtx, reg.JWKSURI, jwk.WithHttprcResourceOption(httprc.WithMinInterval(clientKeySetRefreshInterval)))
+		if err != nil && !errors.Is(err, httprc.ErrResourceAlreadyExists()) {
+			// A failed fetch is not kept, so the keys are fetched again with the next assertion
+			cache.Unregister(context.Background(), reg.JWKSURI)
+			return nil, err
+		}
+	}
+	return cache.Lookup(ctx, reg.JWKSURI)
+}
+
+// replayCache remembers values until they expire, so they can only be used once
+type replayCache struct {
+	entries map[string]time.Time
+	mx      sync.Mutex
+}
+
+func newReplayCache() *replayCache {
+	return &replayCache{
+		entries: make(map[string]time.Time),
+	}
+}
+
+// Add stores the key until its expiry and reports false if the key was already stored
+func (c *replayCache) Add(key string, expiry time.Time) bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	curr := time.Now()
+	for k, exp := range c.entries {
+		if exp.Before(curr) {
+			delete(c.entries, k)
+		}
+	}
+
+	if _, ok := c.entries[key]; ok {
+		return false
+	}
+	c.entries[key] = expiry
+	return true
+}
+
+// ENUM(
+// client_id,
+// client_secret,
+// client_assertion,
+// client_assertion_type,
+// )
+type ClientAuthFormValue string
@@ -0,0 +1,67 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package openid
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// ClientAuthFormValueClientId is a ClientAuthFormValue of type client_id.
+	ClientAuthFormValueClientId ClientAuthFormValue = "client_id"
+	// ClientAuthFormValueClientSecret is a ClientAuthFormValue of type client_secret.
+	ClientAuthFormValueClientSecret ClientAuthFormValue = "client_secret"
+	// ClientAuthFormValueClientAssertion is a ClientAuthFormValue of type client_assertion.
+	ClientAuthFormValueClientAssertion ClientAuthFormValue = "client_assertion"
+	// ClientAuthFormValueClientAssertionType is a ClientAuthFormValue of type client_assertion_type.
+	ClientAuthFormValueClientAssertionType ClientAuthFormValue = "client_assertion_type"
+)
+
+var ErrInvalidClientAuthFormValue = errors.New("not a valid ClientAuthFormValue")
+
+// String implements the Stringer interface.
+func (x ClientAuthFormValue) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ClientAuthFormValue) IsValid() bool {
+	_, err := ParseClientAuthFormValue(string(x))
+	return err == nil
+}
+
+var _ClientAuthFormValueValue = map[string]ClientAuthFormValue{
+	"client_id":             ClientAuthFormValueClientId,
+	"client_secret":         ClientAuthFormValueClientSecret,
+	"client_assertion":      ClientAuthFormValueClientAssertion,
+	"client_assertion_type": ClientAuthFormValueClientAssertionType,
+}
+
+// ParseClientAuthFormValue attempts to convert a string to a ClientAuthFormValue.
+func ParseClientAuthFormValue(name string) (ClientAuthFormValue, error) {
+	if x, ok := _ClientAuthFormValueValue[name]; ok {
+		return x, nil
+	}
+	return ClientAuthFormValue(""), fmt.Errorf("%s is %w", name, ErrInvalidClientAuthFormValue)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ClientAuthFormValue) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ClientAuthFormValue) UnmarshalText(text []byte) error {
+	tmp, err := ParseClientAuthFormValue(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -0,0 +1,342 @@
+package openid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+)
+
+func newTestAssertion(t *testing.T, clientID, audience string, key any, alg jwa.SignatureAlgorithm) string {
+	t.Helper()
+	token, err := jwt.NewBuilder().
+		Issuer(clientID).
+		Subject(clientID).
+		Audience([]string{audience}).
+		Expiration(time.Now().Add(time.Minute)).
+		JwtID(uuid.NewString()).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := jwt.Sign(token, jwt.WithKey(alg, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signed)
+}
+
+func newTestCertificate(t *testing.T, subject string) (*x509.Certificate, jwk.Key) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: subject},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwk.Import(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestAuthenticateClient(t *testing.T) {
+	clientKey := newTestKey(t)
+	cert, certKey := newTestCertificate(t, "client")
+
+	clients := []client.Client{
+		{ClientID: "public"},
+		{ClientID: "secret", ClientSecret: "s3cret"},
+		{ClientID: "secret-jwt", ClientSecret: "a-secret-which-is-long-enough-for-hs256", TokenEndpointAuthMethod: enums.EndpointAuthMethodClientSecretJwt},
+		{ClientID: "key", JWKS: newTestPublicKeySet(t, clientKey)},
+		{ClientID: "key-method", JWKS: newTestPublicKeySet(t, clientKey), TokenEndpointAuthMethod: enums.EndpointAuthMethodPrivateKeyJwt},
+		{ClientID: "tls", TLSClientAuthSubjectDN: "CN=client", TokenEndpointAuthMethod: enums.EndpointAuthMethodTlsClientAuth},
+		{ClientID: "tls-unregistered-method", TLSClientAuthSubjectDN: "CN=client"},
+		{ClientID: "self-signed", JWKS: newTestPublicKeySet(t, certKey), TokenEndpointAuthMethod: enums.EndpointAuthMethodSelfSignedTlsClientAuth},
+	}
+	// The certificate is only read when the server is started
+	ip := newTestProvider(t, WithClients(clients...), WithTLS("cert.pem", "key.pem"))
+	tokenURL := testOrigin + ip.basePath + "/token"
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		basicAuth  []string
+		cert       *x509.Certificate
+		wantMethod enums.EndpointAuthMethod
+		wantErr    TokenErrorType
+	}{
+		{
+			name:       "public client without credentials",
+			form:       url.Values{"client_id": {"public"}},
+			wantMethod: enums.EndpointAuthMethodNone,
+		},
+		{
+			name:       "client_secret_basic",
+			basicAuth:  []string{"secret", "s3cret"},
+			wantMethod: enums.EndpointAuthMethodClientSecretBasic,
+		},
+		{
+			name:       "client_secret_post",
+			form:       url.Values{"client_id": {"secret"}, "client_secret": {"s3cret"}},
+			wantMethod: enums.EndpointAuthMethodClientSecretPost,
+		},
+		{
+			name:    "wrong secret",
+			form:    url.Values{"client_id": {"secret"}, "client_secret": {"wrong"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:    "secret client without credentials",
+			form:    url.Values{"client_id": {"secret"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:    "key client without credentials",
+			form:    url.Values{"client_id": {"key"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:    "certificate client without registered method and credentials",
+			form:    url.Values{"client_id": {"tls-unregistered-method"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:    "unknown client",
+			form:    url.Values{"client_id": {"unknown"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name: "client_secret_jwt",
+			form: url.Values{
+				"client_assertion_type": {clientAssertionTypeJWTBearer},
+				"client_assertion":      {newTestAssertion(t, "secret-jwt", tokenURL, []byte("a-secret-which-is-long-enough-for-hs256"), jwa.HS256())},
+			},
+			wantMethod: enums.EndpointAuthMethodClientSecretJwt,
+		},
+		{
+			name: "private_key_jwt",
+			form: url.Values{
+				"client_assertion_type": {clientAssertionTypeJWTBearer},
+				"client_assertion":      {newTestAssertion(t, "key-method", tokenURL, clientKey, jwa.ES256())},
+			},
+			wantMethod: enums.EndpointAuthMethodPrivateKeyJwt,
+		},
+		{
+			name: "private_key_jwt with the wrong audience",
+			form: url.Values{
+				"client_assertion_type": {clientAssertionTypeJWTBearer},
+				"client_assertion":      {newTestAssertion(t, "key-method", "https://other.example", clientKey, jwa.ES256())},
+			},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name: "private_key_jwt with an unknown key",
+			form: url.Values{
+				"client_assertion_type": {clientAssertionTypeJWTBearer},
+				"client_assertion":      {newTestAssertion(t, "key-method", tokenURL, newTestKey(t), jwa.ES256())},
+			},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name: "unsupported client_assertion_type",
+			form: url.Values{
+				"client_assertion_type": {"urn:example"},
+				"client_assertion":      {newTestAssertion(t, "key-method", tokenURL, clientKey, jwa.ES256())},
+			},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:    "registered method is required",
+			form:    url.Values{"client_id": {"secret-jwt"}, "client_secret": {"a-secret-which-is-long-enough-for-hs256"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:    "tls_client_auth without certificate",
+			form:    url.Values{"client_id": {"tls"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:    "tls_client_auth with an untrusted certificate",
+			form:    url.Values{"client_id": {"tls"}},
+			cert:    cert,
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+		{
+			name:       "self_signed_tls_client_auth",
+			form:       url.Values{"client_id": {"self-signed"}},
+			cert:       cert,
+			wantMethod: enums.EndpointAuthMethodSelfSignedTlsClientAuth,
+		},
+		{
+			name:    "self_signed_tls_client_auth without certificate",
+			form:    url.Values{"client_id": {"self-signed"}},
+			wantErr: TokenErrorTypeInvalidClient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRequest(t, ip, http.MethodPost, "/token", tt.form)
+			if tt.basicAuth != nil {
+				r.SetBasicAuth(tt.basicAuth[0], tt.basicAuth[1])
+			}
+			if tt.cert != nil {
+				r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tt.cert}}
+			}
+
+			reg, method, tokenErr := ip.authenticateClient(r)
+			if tt.wantErr != "" {
+				if tokenErr == nil || tokenErr.ErrorType != tt.wantErr {
+					t.Fatalf("expected error %s, got %v", tt.wantErr, tokenErr)
+				}
+				return
+			}
+			if tokenErr != nil {
+				t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+			}
+			if method != tt.wantMethod {
+				t.Errorf("expected method %s, got %s", tt.wantMethod, method)
+			}
+			if r.Form.Get("client_id") != reg.ClientID {
+				t.Errorf("expected the client_id %s to be set in the form, got %s", reg.ClientID, r.Form.Get("client_id"))
+			}
+		})
+	}
+}
+
+func TestAuthenticateClientAssertionReplay(t *testing.T) {
+	clientKey := newTestKey(t)
+	ip := newTestProvider(t, WithClients(client.Client{ClientID: "key", JWKS: newTestPublicKeySet(t, clientKey)}))
+	form := url.Values{
+		"client_assertion_type": {clientAssertionTypeJWTBearer},
+		"client_assertion":      {newTestAssertion(t, "key", testOrigin+ip.basePath+"/token", clientKey, jwa.ES256())},
+	}
+
+	if _, _, tokenErr := ip.authenticateClient(newTestRequest(t, ip, http.MethodPost, "/token", form)); tokenErr != nil {
+		t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+	}
+	_, _, tokenErr := ip.authenticateClient(newTestRequest(t, ip, http.MethodPost, "/token", form))
+	if tokenErr == nil || tokenErr.ErrorType != TokenErrorTypeInvalidClient {
+		t.Fatalf("expected the replayed assertion to be rejected, got %v", tokenErr)
+	}
+}
+
+func TestAuthenticateClientKeySetURI(t *testing.T) {
+	clientKey := newTestKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newTestPublicKeySet(t, clientKey))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		opts    []OptionFn
+		wantErr bool
+	}{
+		{
+			name:    "internal addresses are not fetched",
+			wantErr: true,
+		},
+		{
+			name: "keys of the configured client",
+			opts: []OptionFn{WithOutboundHTTPClient(server.Client())},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]OptionFn{WithClients(client.Client{ClientID: "key", JWKSURI: server.URL})}, tt.opts...)
+			ip := newTestProvider(t, opts...)
+			form := url.Values{
+				"client_assertion_type": {clientAssertionTypeJWTBearer},
+				"client_assertion":      {newTestAssertion(t, "key", testOrigin+ip.basePath+"/token", clientKey, jwa.ES256())},
+			}
+
+			_, _, tokenErr := ip.authenticateClient(newTestRequest(t, ip, http.MethodPost, "/token", form))
+			if tt.wantErr && (tokenErr == nil || tokenErr.ErrorType != TokenErrorTypeInvalidClient) {
+				t.Fatalf("expected invalid_client, got %v", tokenErr)
+			}
+			if !tt.wantErr && tokenErr != nil {
+				t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+			}
+		})
+	}
+}
+
+func TestAuthenticateClientAssertionLifeTime(t *testing.T) {
+	clientKey := newTestKey(t)
+	ip := newTestProvider(t, WithClients(client.Client{ClientID: "key", JWKS: newTestPublicKeySet(t, clientKey)}))
+
+	token, err := jwt.NewBuilder().
+		Issuer("key").
+		Subject("key").
+		Audience([]string{testOrigin + ip.basePath + "/token"}).
+		Expiration(time.Now().Add(time.Hour)).
+		JwtID(uuid.NewString()).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.ES256(), clientKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{
+		"client_assertion_type": {clientAssertionTypeJWTBearer},
+		"client_assertion":      {string(signed)},
+	}
+	_, _, tokenErr := ip.authenticateClient(newTestRequest(t, ip, http.MethodPost, "/token", form))
+	if tokenErr == nil || tokenErr.ErrorType != TokenErrorTypeInvalidClient {
+		t.Fatalf("expected the long-living assertion to be rejected, got %v", tokenErr)
+	}
+}
+
+func TestClientAuthMethodsWithoutTLS(t *testing.T) {
+	cert, certKey := newTestCertificate(t, "client")
+	ip := newTestProvider(t, WithClients(client.Client{ClientID: "self-signed", JWKS: newTestPublicKeySet(t, certKey), TokenEndpointAuthMethod: enums.EndpointAuthMethodSelfSignedTlsClientAuth}))
+
+	for _, method := range ip.clientAuthMethods {
+		if isTLSClientAuthMethod(method) {
+			t.Errorf("expected %s to be disabled without tls", method)
+		}
+	}
+
+	r := newTestRequest(t, ip, http.MethodPost, "/token", url.Values{"client_id": {"self-signed"}})
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if _, _, tokenErr := ip.authenticateClient(r); tokenErr == nil || tokenErr.ErrorType != TokenErrorTypeInvalidClient {
+		t.Fatalf("expected invalid_client, got %v", tokenErr)
+	}
+}
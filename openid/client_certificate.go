@@ -0,0 +1,113 @@
+package openid
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+func isTLSClientAuthMethod(method enums.EndpointAuthMethod) bool {
+	return method == enums.EndpointAuthMethodTlsClientAuth || method == enums.EndpointAuthMethodSelfSignedTlsClientAuth
+}
+
+// authenticateClientCertificate authenticates the client with the certificate of the mutual-TLS connection (RFC 8705).
+// The server only requests the certificate, so it is verified here depending on the registered method.
+func (ip *IdentitiyProvider) authenticateClientCertificate(r *http.Request, reg client.Client) (client.Client, enums.EndpointAuthMethod, *TokenError) {
+	cert := peerCertificate(r)
+	if cert == nil {
+		return client.Client{}, "", &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: "no client certificate provided",
+		}
+	}
+
+	switch reg.TokenEndpointAuthMethod {
+	case enums.EndpointAuthMethodTlsClientAuth:
+		intermediates := x509.NewCertPool()
+		for _, c := range r.TLS.PeerCertificates[1:] {
+			intermediates.AddCert(c)
+		}
+		if _, err := cert.Verify(x509.VerifyOptions{
+			Roots:         ip.tlsClientCAs,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}); err != nil {
+			return client.Client{}, "", &TokenError{
+				ErrorType:        TokenErrorTypeInvalidClient,
+				ErrorDescription: err.Error(),
+			}
+		}
+		if reg.TLSClientAuthSubjectDN == "" || cert.Subject.String() != reg.TLSClientAuthSubjectDN {
+			return client.Client{}, "", &TokenError{
+				ErrorType:        TokenErrorTypeInvalidClient,
+				ErrorDescription: "subject of the client certificate does not match",
+			}
+		}
+
+	case enums.EndpointAuthMethodSelfSignedTlsClientAuth:
+		set, err := ip.clientKeySet(r.Context(), reg)
+		if err != nil {
+			return client.Client{}, "", &TokenError{
+				ErrorType:        TokenErrorTypeInvalidClient,
+				ErrorDescription: err.Error(),
+			}
+		}
+		if !certificateInKeySet(cert, set) {
+			return client.Client{}, "", &TokenError{
+				ErrorType:        TokenErrorTypeInvalidClient,
+				ErrorDescription: "client certificate is not registered",
+			}
+		}
+	}
+
+	return reg, reg.TokenEndpointAuthMethod, nil
+}
+
+// certificateInKeySet reports if the public key of the certificate is one of the keys of the set
+func certificateInKeySet(cert *x509.Certificate, set jwk.Set) bool {
+	certKey, err := jwk.Import(cert.PublicKey)
+	if err != nil {
+		return false
+	}
+	certThumbprint, err := certKey.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return false
+	}
+
+	for i := range set.Len() {
+		key, _ := set.Key(i)
+		thumbprint, err := key.Thumbprint(crypto.SHA256)
+		if err == nil && bytes.Equal(thumbprint, certThumbprint) {
+			return true
+		}
+	}
+	return false
+}
+
+func peerCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return r.TLS.PeerCertificates[0]
+}
+
+// tokenConfirmation returns the confirmation the issued access tokens are bound to.
+// If the client has used a certificate, the tokens are bound to it even for public clients.
+func tokenConfirmation(r *http.Request) *types.Confirmation {
+	cert := peerCertificate(r)
+	if cert == nil {
+		return nil
+	}
+	thumbprint := sha256.Sum256(cert.Raw)
+	return &types.Confirmation{
+		X5tS256: base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+	}
+}
@@ -0,0 +1,201 @@
+package openid
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	dpopHeader      = "DPoP"
+	dpopNonceHeader = "DPoP-Nonce"
+	dpopProofType   = "dpop+jwt"
+	dpopProofMaxAge = 60 * time.Second
+)
+
+var (
+	ErrInvalidDPoPProof = errors.New("invalid dpop proof")
+	ErrUseDPoPNonce     = errors.New("dpop proof has to contain the nonce of the server")
+)
+
+// validateDPoPProof validates the DPoP proof of the request (RFC 9449) and returns the thumbprint of its key.
+// If the proof is sent together with an access token, the ath claim has to be the hash of it.
+func (ip *IdentitiyProvider) validateDPoPProof(r *http.Request, accessToken string) (string, error) {
+	proofs := r.Header.Values(dpopHeader)
+	if len(proofs) != 1 {
+		return "", errors.Join(ErrInvalidDPoPProof, errors.New("exactly one proof is required"))
+	}
+
+	msg, err := jws.ParseString(proofs[0])
+	if err != nil || len(msg.Signatures()) != 1 {
+		return "", ErrInvalidDPoPProof
+	}
+	headers := msg.Signatures()[0].ProtectedHeaders()
+
+	if typ, _ := headers.Type(); typ != dpopProofType {
+		return "", errors.Join(ErrInvalidDPoPProof, errors.New("invalid typ header"))
+	}
+	alg, ok := headers.Algorithm()
+	if !ok || !enums.SigningAlgValue(alg.String()).IsValid() || enums.SigningAlgValue(alg.String()).IsSymmetric() {
+		return "", errors.Join(ErrInvalidDPoPProof, errors.New("unsupported alg header"))
+	}
+	key, ok := headers.JWK()
+	if !ok {
+		return "", errors.Join(ErrInvalidDPoPProof, errors.New("missing jwk header"))
+	}
+	if isPrivate, err := jwk.IsPrivateKey(key); err != nil || isPrivate {
+		return "", errors.Join(ErrInvalidDPoPProof, errors.New("jwk header has to be a public key"))
+	}
+
+	token, err := jwt.ParseString(
+		proofs[0],
+		jwt.WithKey(alg, key),
+		jwt.WithRequiredClaim(jwt.JwtIDKey),
+		jwt.WithRequiredClaim(jwt.IssuedAtKey),
+		jwt.WithClaimValue(enums.ClaimHtm.String(), r.Method),
+		jwt.WithClaimValue(enums.ClaimHtu.String(), ip.requestURL(r)),
+	)
+	if err != nil {
+		return "", errors.Join(ErrInvalidDPoPProof, err)
+	}
+
+	iat, _ := token.IssuedAt()
+	if time.Since(iat) > dpopProofMaxAge {
+		return "", errors.Join(ErrInvalidDPoPProof, errors.New("proof is too old"))
+	}
+
+	if accessToken != "" {
+		var ath string
+		token.Get(enums.ClaimAth.String(), &ath)
+		athSha := sha256.Sum256([]byte(accessToken))
+		if ath != base64.RawURLEncoding.EncodeToString(athSha[:]) {
+			return "", errors.Join(ErrInvalidDPoPProof, errors.New("ath does not match the access token"))
+		}
+	}
+
+	if ip.dpopNonce != nil {
+		var nonce string
+		token.Get(enums.ClaimNonce.String(), &nonce)
+		if !ip.dpopNonce.IsValid(nonce) {
+			return "", ErrUseDPoPNonce
+		}
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errors.Join(ErrInvalidDPoPProof, err)
+	}
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	jti, _ := token.JwtID()
+	if !ip.replayCache.Add(dpopHeader+":"+jkt+":"+jti, iat.Add(dpopProofMaxAge)) {
+		return "", errors.Join(ErrInvalidDPoPProof, errors.New("proof was already used"))
+	}
+
+	return jkt, nil
+}
+
+// setDPoPNonce sends the current nonce to the client, so it can be used in the next proof
+func (ip *IdentitiyProvider) setDPoPNonce(w http.ResponseWriter) {
+	if ip.dpopNonce != nil {
+		w.Header().Set(dpopNonceHeader, ip.dpopNonce.Current())
+	}
+}
+
+// requestURL is the url of the request without query and fragment as the client has called it
+func (ip *IdentitiyProvider) requestURL(r *http.Request) string {
+	j := jose.GetJose(r.Context())
+	return strings.TrimSuffix(j.Issuer(), ip.basePath) + r.URL.Path
+}
+
+// confirmationClaim returns the keys the token is bound to
+func confirmationClaim(token jwt.Token) *types.Confirmation {
+	var claim map[string]any
+	if err := token.Get(enums.ClaimCnf.String(), &claim); err != nil {
+		return nil
+	}
+
+	var cnf types.Confirmation
+	cnf.JKT, _ = claim["jkt"].(string)
+	cnf.X5tS256, _ = claim["x5t#S256"].(string)
+	return &cnf
+}
+
+// checkTokenBinding verifies that the request is made with the keys the token is bound to
+func checkTokenBinding(token jwt.Token, cnf *types.Confirmation) error {
+	bound := confirmationClaim(token)
+	if bound == nil {
+		return nil
+	}
+
+	if bound.JKT != "" && (cnf == nil || cnf.JKT != bound.JKT) {
+		return errors.New("token is bound to another dpop key")
+	}
+	if bound.X5tS256 != "" && (cnf == nil || cnf.X5tS256 != bound.X5tS256) {
+		return errors.New("token is bound to another client certificate")
+	}
+	return nil
+}
+
+// tokenType returns the type of the access token for the token response
+func tokenType(cnf *types.Confirmation) string {
+	if cnf != nil && cnf.JKT != "" {
+		return "DPoP"
+	}
+	return "Bearer"
+}
+
+// dpopNonce is a server nonce which is rotated after its lifetime.
+// The previous nonce stays valid, so clients that just received it are not rejected.
+type dpopNonce struct {
+	lifeTime  time.Duration
+	current   string
+	previous  string
+	rotatedAt time.Time
+	mx        sync.Mutex
+}
+
+func newDPoPNonce(lifeTime time.Duration) *dpopNonce {
+	return &dpopNonce{
+		lifeTime:  lifeTime,
+		current:   oauth2.GenerateVerifier(),
+		rotatedAt: time.Now(),
+	}
+}
+
+func (n *dpopNonce) rotate() {
+	if time.Since(n.rotatedAt) < n.lifeTime {
+		return
+	}
+	n.previous = n.current
+	n.current = oauth2.GenerateVerifier()
+	n.rotatedAt = time.Now()
+}
+
+func (n *dpopNonce) Current() string {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	n.rotate()
+	return n.current
+}
+
+func (n *dpopNonce) IsValid(nonce string) bool {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	n.rotate()
+	return nonce != "" && slices.Contains([]string{n.current, n.previous}, nonce)
+}
@@ -0,0 +1,299 @@
+package openid
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+)
+
+type testProof struct {
+	alg       jwa.SignatureAlgorithm
+	signKey   any
+	headerKey jwk.Key
+	typ       string
+	claims    map[string]any
+}
+
+// newTestProof signs the claims with the key and puts the header key into the jwk header
+func newTestProof(t *testing.T, proof testProof) string {
+	t.Helper()
+	headers := jws.NewHeaders()
+	if err := headers.Set(jws.TypeKey, proof.typ); err != nil {
+		t.Fatal(err)
+	}
+	if err := headers.Set(jws.JWKKey, proof.headerKey); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(proof.claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := jws.Sign(payload, jws.WithKey(proof.alg, proof.signKey, jws.WithProtectedHeaders(headers)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signed)
+}
+
+func TestValidateDPoPProof(t *testing.T) {
+	ip := newTestProvider(t)
+	htu := testOrigin + ip.basePath + "/token"
+
+	key := newTestKey(t)
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	symmetricKey, err := jwk.Import([]byte("a-secret-which-is-long-enough-for-hs256"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken := "access-token"
+	athSha := sha256.Sum256([]byte(accessToken))
+	ath := base64.RawURLEncoding.EncodeToString(athSha[:])
+
+	claims := func(modify func(map[string]any)) map[string]any {
+		c := map[string]any{
+			"jti": uuid.NewString(),
+			"htm": http.MethodPost,
+			"htu": htu,
+			"iat": time.Now().Unix(),
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+	valid := func(modify func(*testProof)) string {
+		proof := testProof{
+			alg:       jwa.ES256(),
+			signKey:   key,
+			headerKey: publicKey,
+			typ:       dpopProofType,
+			claims:    claims(nil),
+		}
+		if modify != nil {
+			modify(&proof)
+		}
+		return newTestProof(t, proof)
+	}
+
+	tests := []struct {
+		name        string
+		proofs      []string
+		accessToken string
+		wantErr     error
+	}{
+		{
+			name:   "valid proof",
+			proofs: []string{valid(nil)},
+		},
+		{
+			name:    "missing proof",
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name:    "multiple proofs",
+			proofs:  []string{valid(nil), valid(nil)},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name:    "malformed proof",
+			proofs:  []string{"not-a-jwt"},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name:    "wrong typ",
+			proofs:  []string{valid(func(p *testProof) { p.typ = "JWT" })},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name: "symmetric alg",
+			proofs: []string{valid(func(p *testProof) {
+				p.alg = jwa.HS256()
+				p.signKey = symmetricKey
+				p.headerKey = symmetricKey
+			})},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name:    "private key in jwk header",
+			proofs:  []string{valid(func(p *testProof) { p.headerKey = key })},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name:    "signed by another key",
+			proofs:  []string{valid(func(p *testProof) { p.signKey = newTestKey(t) })},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name: "missing jti",
+			proofs: []string{valid(func(p *testProof) {
+				p.claims = claims(func(c map[string]any) { delete(c, "jti") })
+			})},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name: "wrong htm",
+			proofs: []string{valid(func(p *testProof) {
+				p.claims = claims(func(c map[string]any) { c["htm"] = http.MethodGet })
+			})},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name: "wrong htu",
+			proofs: []string{valid(func(p *testProof) {
+				p.claims = claims(func(c map[string]any) { c["htu"] = testOrigin + ip.basePath + "/userinfo" })
+			})},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name: "proof is too old",
+			proofs: []string{valid(func(p *testProof) {
+				p.claims = claims(func(c map[string]any) { c["iat"] = time.Now().Add(-2 * dpopProofMaxAge).Unix() })
+			})},
+			wantErr: ErrInvalidDPoPProof,
+		},
+		{
+			name: "matching ath",
+			proofs: []string{valid(func(p *testProof) {
+				p.claims = claims(func(c map[string]any) { c["ath"] = ath })
+			})},
+			accessToken: accessToken,
+		},
+		{
+			name:        "missing ath",
+			proofs:      []string{valid(nil)},
+			accessToken: accessToken,
+			wantErr:     ErrInvalidDPoPProof,
+		},
+		{
+			name: "ath of another access token",
+			proofs: []string{valid(func(p *testProof) {
+				p.claims = claims(func(c map[string]any) { c["ath"] = ath })
+			})},
+			accessToken: "another-access-token",
+			wantErr:     ErrInvalidDPoPProof,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRequest(t, ip, http.MethodPost, "/token", nil)
+			for _, proof := range tt.proofs {
+				r.Header.Add(dpopHeader, proof)
+			}
+
+			jkt, err := ip.validateDPoPProof(r, tt.accessToken)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if jkt == "" {
+				t.Error("expected the thumbprint of the proof key")
+			}
+		})
+	}
+}
+
+func TestValidateDPoPProofReplay(t *testing.T) {
+	ip := newTestProvider(t)
+	key := newTestKey(t)
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := newTestProof(t, testProof{
+		alg:       jwa.ES256(),
+		signKey:   key,
+		headerKey: publicKey,
+		typ:       dpopProofType,
+		claims: map[string]any{
+			"jti": uuid.NewString(),
+			"htm": http.MethodPost,
+			"htu": testOrigin + ip.basePath + "/token",
+			"iat": time.Now().Unix(),
+		},
+	})
+
+	r := newTestRequest(t, ip, http.MethodPost, "/token", nil)
+	r.Header.Set(dpopHeader, proof)
+	if _, err = ip.validateDPoPProof(r, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r = newTestRequest(t, ip, http.MethodPost, "/token", nil)
+	r.Header.Set(dpopHeader, proof)
+	if _, err = ip.validateDPoPProof(r, ""); !errors.Is(err, ErrInvalidDPoPProof) {
+		t.Fatalf("expected the replayed proof to be rejected, got %v", err)
+	}
+}
+
+func TestValidateDPoPProofNonce(t *testing.T) {
+	ip := newTestProvider(t)
+	ip.dpopNonce = newDPoPNonce(time.Minute)
+	key := newTestKey(t)
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		nonce   string
+		wantErr error
+	}{
+		{name: "missing nonce", wantErr: ErrUseDPoPNonce},
+		{name: "unknown nonce", nonce: "unknown", wantErr: ErrUseDPoPNonce},
+		{name: "current nonce", nonce: ip.dpopNonce.Current()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := map[string]any{
+				"jti": uuid.NewString(),
+				"htm": http.MethodPost,
+				"htu": testOrigin + ip.basePath + "/token",
+				"iat": time.Now().Unix(),
+			}
+			if tt.nonce != "" {
+				claims["nonce"] = tt.nonce
+			}
+			r := newTestRequest(t, ip, http.MethodPost, "/token", nil)
+			r.Header.Set(dpopHeader, newTestProof(t, testProof{
+				alg:       jwa.ES256(),
+				signKey:   key,
+				headerKey: publicKey,
+				typ:       dpopProofType,
+				claims:    claims,
+			}))
+
+			_, err := ip.validateDPoPProof(r, "")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+package enums
+
+// ENUM(rfc9068, legacy)
+type AccessTokenFormat string
@@ -0,0 +1,69 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package enums
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// AccessTokenFormatRfc9068 is a AccessTokenFormat of type rfc9068.
+	AccessTokenFormatRfc9068 AccessTokenFormat = "rfc9068"
+	// AccessTokenFormatLegacy is a AccessTokenFormat of type legacy.
+	AccessTokenFormatLegacy AccessTokenFormat = "legacy"
+)
+
+var ErrInvalidAccessTokenFormat = errors.New("not a valid AccessTokenFormat")
+
+// AccessTokenFormatValues returns a list of the values for AccessTokenFormat
+func AccessTokenFormatValues() []AccessTokenFormat {
+	return []AccessTokenFormat{
+		AccessTokenFormatRfc9068,
+		AccessTokenFormatLegacy,
+	}
+}
+
+// String implements the Stringer interface.
+func (x AccessTokenFormat) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x AccessTokenFormat) IsValid() bool {
+	_, err := ParseAccessTokenFormat(string(x))
+	return err == nil
+}
+
+var _AccessTokenFormatValue = map[string]AccessTokenFormat{
+	"rfc9068": AccessTokenFormatRfc9068,
+	"legacy":  AccessTokenFormatLegacy,
+}
+
+// ParseAccessTokenFormat attempts to convert a string to a AccessTokenFormat.
+func ParseAccessTokenFormat(name string) (AccessTokenFormat, error) {
+	if x, ok := _AccessTokenFormatValue[name]; ok {
+		return x, nil
+	}
+	return AccessTokenFormat(""), fmt.Errorf("%s is %w", name, ErrInvalidAccessTokenFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x AccessTokenFormat) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *AccessTokenFormat) UnmarshalText(text []byte) error {
+	tmp, err := ParseAccessTokenFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -20,6 +20,15 @@ package enums
 // at_hash,
 // nonce,
 // auth_time,
+// acr,
+// amr,
+// client_id,
+// act,
+// cnf,
+// htm,
+// htu,
+// ath,
+// events,
 // token_type = tt,
 // )
 type Claim string
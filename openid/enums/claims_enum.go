@@ -50,6 +50,24 @@ const (
 	ClaimNonce Claim = "nonce"
 	// ClaimAuthTime is a Claim of type auth_time.
 	ClaimAuthTime Claim = "auth_time"
+	// ClaimAcr is a Claim of type acr.
+	ClaimAcr Claim = "acr"
+	// ClaimAmr is a Claim of type amr.
+	ClaimAmr Claim = "amr"
+	// ClaimClientId is a Claim of type client_id.
+	ClaimClientId Claim = "client_id"
+	// ClaimAct is a Claim of type act.
+	ClaimAct Claim = "act"
+	// ClaimCnf is a Claim of type cnf.
+	ClaimCnf Claim = "cnf"
+	// ClaimHtm is a Claim of type htm.
+	ClaimHtm Claim = "htm"
+	// ClaimHtu is a Claim of type htu.
+	ClaimHtu Claim = "htu"
+	// ClaimAth is a Claim of type ath.
+	ClaimAth Claim = "ath"
+	// ClaimEvents is a Claim of type events.
+	ClaimEvents Claim = "events"
 	// ClaimTokenType is a Claim of type token_type.
 	ClaimTokenType Claim = "tt"
 )
@@ -78,6 +96,15 @@ func ClaimValues() []Claim {
 		ClaimAtHash,
 		ClaimNonce,
 		ClaimAuthTime,
+		ClaimAcr,
+		ClaimAmr,
+		ClaimClientId,
+		ClaimAct,
+		ClaimCnf,
+		ClaimHtm,
+		ClaimHtu,
+		ClaimAth,
+		ClaimEvents,
 		ClaimTokenType,
 	}
 }
@@ -114,6 +141,15 @@ var _ClaimValue = map[string]Claim{
 	"at_hash":            ClaimAtHash,
 	"nonce":              ClaimNonce,
 	"auth_time":          ClaimAuthTime,
+	"acr":                ClaimAcr,
+	"amr":                ClaimAmr,
+	"client_id":          ClaimClientId,
+	"act":                ClaimAct,
+	"cnf":                ClaimCnf,
+	"htm":                ClaimHtm,
+	"htu":                ClaimHtu,
+	"ath":                ClaimAth,
+	"events":             ClaimEvents,
 	"tt":                 ClaimTokenType,
 }
 
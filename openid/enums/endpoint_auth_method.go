@@ -1,4 +1,4 @@
 package enums
 
-// ENUM(client_secret_basic, client_secret_post)
+// ENUM(client_secret_basic, client_secret_post, client_secret_jwt, private_key_jwt, none, tls_client_auth, self_signed_tls_client_auth)
 type EndpointAuthMethod string
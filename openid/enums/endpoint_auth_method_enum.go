@@ -16,6 +16,16 @@ const (
 	EndpointAuthMethodClientSecretBasic EndpointAuthMethod = "client_secret_basic"
 	// EndpointAuthMethodClientSecretPost is a EndpointAuthMethod of type client_secret_post.
 	EndpointAuthMethodClientSecretPost EndpointAuthMethod = "client_secret_post"
+	// EndpointAuthMethodClientSecretJwt is a EndpointAuthMethod of type client_secret_jwt.
+	EndpointAuthMethodClientSecretJwt EndpointAuthMethod = "client_secret_jwt"
+	// EndpointAuthMethodPrivateKeyJwt is a EndpointAuthMethod of type private_key_jwt.
+	EndpointAuthMethodPrivateKeyJwt EndpointAuthMethod = "private_key_jwt"
+	// EndpointAuthMethodNone is a EndpointAuthMethod of type none.
+	EndpointAuthMethodNone EndpointAuthMethod = "none"
+	// EndpointAuthMethodTlsClientAuth is a EndpointAuthMethod of type tls_client_auth.
+	EndpointAuthMethodTlsClientAuth EndpointAuthMethod = "tls_client_auth"
+	// EndpointAuthMethodSelfSignedTlsClientAuth is a EndpointAuthMethod of type self_signed_tls_client_auth.
+	EndpointAuthMethodSelfSignedTlsClientAuth EndpointAuthMethod = "self_signed_tls_client_auth"
 )
 
 var ErrInvalidEndpointAuthMethod = errors.New("not a valid EndpointAuthMethod")
@@ -25,6 +35,11 @@ func EndpointAuthMethodValues() []EndpointAuthMethod {
 	return []EndpointAuthMethod{
 		EndpointAuthMethodClientSecretBasic,
 		EndpointAuthMethodClientSecretPost,
+		EndpointAuthMethodClientSecretJwt,
+		EndpointAuthMethodPrivateKeyJwt,
+		EndpointAuthMethodNone,
+		EndpointAuthMethodTlsClientAuth,
+		EndpointAuthMethodSelfSignedTlsClientAuth,
 	}
 }
 
@@ -41,8 +56,13 @@ func (x EndpointAuthMethod) IsValid() bool {
 }
 
 var _EndpointAuthMethodValue = map[string]EndpointAuthMethod{
-	"client_secret_basic": EndpointAuthMethodClientSecretBasic,
-	"client_secret_post":  EndpointAuthMethodClientSecretPost,
+	"client_secret_basic":         EndpointAuthMethodClientSecretBasic,
+	"client_secret_post":          EndpointAuthMethodClientSecretPost,
+	"client_secret_jwt":           EndpointAuthMethodClientSecretJwt,
+	"private_key_jwt":             EndpointAuthMethodPrivateKeyJwt,
+	"none":                        EndpointAuthMethodNone,
+	"tls_client_auth":             EndpointAuthMethodTlsClientAuth,
+	"self_signed_tls_client_auth": EndpointAuthMethodSelfSignedTlsClientAuth,
 }
 
 // ParseEndpointAuthMethod attempts to convert a string to a EndpointAuthMethod.
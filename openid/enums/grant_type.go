@@ -3,6 +3,8 @@ package enums
 // ENUM(
 // authorization_code,
 // refresh_token,
+// client_credentials,
 // token_exchange = urn:ietf:params:oauth:grant-type:token-exchange,
+// device_code = urn:ietf:params:oauth:grant-type:device_code,
 // )
 type GrantType string
@@ -16,8 +16,12 @@ const (
 	GrantTypeAuthorizationCode GrantType = "authorization_code"
 	// GrantTypeRefreshToken is a GrantType of type refresh_token.
 	GrantTypeRefreshToken GrantType = "refresh_token"
+	// GrantTypeClientCredentials is a GrantType of type client_credentials.
+	GrantTypeClientCredentials GrantType = "client_credentials"
 	// GrantTypeTokenExchange is a GrantType of type token_exchange.
 	GrantTypeTokenExchange GrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// GrantTypeDeviceCode is a GrantType of type device_code.
+	GrantTypeDeviceCode GrantType = "urn:ietf:params:oauth:grant-type:device_code"
 )
 
 var ErrInvalidGrantType = errors.New("not a valid GrantType")
@@ -27,7 +31,9 @@ func GrantTypeValues() []GrantType {
 	return []GrantType{
 		GrantTypeAuthorizationCode,
 		GrantTypeRefreshToken,
+		GrantTypeClientCredentials,
 		GrantTypeTokenExchange,
+		GrantTypeDeviceCode,
 	}
 }
 
@@ -46,7 +52,9 @@ func (x GrantType) IsValid() bool {
 var _GrantTypeValue = map[string]GrantType{
 	"authorization_code": GrantTypeAuthorizationCode,
 	"refresh_token":      GrantTypeRefreshToken,
+	"client_credentials": GrantTypeClientCredentials,
 	"urn:ietf:params:oauth:grant-type:token-exchange": GrantTypeTokenExchange,
+	"urn:ietf:params:oauth:grant-type:device_code":    GrantTypeDeviceCode,
 }
 
 // ParseGrantType attempts to convert a string to a GrantType.
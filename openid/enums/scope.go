@@ -32,14 +32,11 @@ func (x Scopes) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the text unmarshaller method.
+// Unknown scopes are kept, because the identity provider can accept custom scopes and validates them itself.
 func (x *Scopes) UnmarshalText(text []byte) error {
 	var tmps Scopes
 	for field := range bytes.FieldsSeq(text) {
-		tmp, err := ParseScope(string(field))
-		if err != nil {
-			return err
-		}
-		tmps = append(tmps, tmp)
+		tmps = append(tmps, Scope(field))
 	}
 	*x = tmps
 	return nil
@@ -0,0 +1,4 @@
+package enums
+
+// ENUM(authorization_code_replay)
+type SecurityEventType string
@@ -0,0 +1,65 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package enums
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// SecurityEventTypeAuthorizationCodeReplay is a SecurityEventType of type authorization_code_replay.
+	SecurityEventTypeAuthorizationCodeReplay SecurityEventType = "authorization_code_replay"
+)
+
+var ErrInvalidSecurityEventType = errors.New("not a valid SecurityEventType")
+
+// SecurityEventTypeValues returns a list of the values for SecurityEventType
+func SecurityEventTypeValues() []SecurityEventType {
+	return []SecurityEventType{
+		SecurityEventTypeAuthorizationCodeReplay,
+	}
+}
+
+// String implements the Stringer interface.
+func (x SecurityEventType) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x SecurityEventType) IsValid() bool {
+	_, err := ParseSecurityEventType(string(x))
+	return err == nil
+}
+
+var _SecurityEventTypeValue = map[string]SecurityEventType{
+	"authorization_code_replay": SecurityEventTypeAuthorizationCodeReplay,
+}
+
+// ParseSecurityEventType attempts to convert a string to a SecurityEventType.
+func ParseSecurityEventType(name string) (SecurityEventType, error) {
+	if x, ok := _SecurityEventTypeValue[name]; ok {
+		return x, nil
+	}
+	return SecurityEventType(""), fmt.Errorf("%s is %w", name, ErrInvalidSecurityEventType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x SecurityEventType) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *SecurityEventType) UnmarshalText(text []byte) error {
+	tmp, err := ParseSecurityEventType(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -0,0 +1,12 @@
+package enums
+
+import "strings"
+
+// These are the algorithms a client can use to sign its JWTs
+// ENUM(RS256, RS384, RS512, PS256, PS384, PS512, ES256, ES384, ES512, EdDSA, HS256, HS384, HS512)
+type SigningAlgValue string
+
+// IsSymmetric reports if the algorithm uses a shared secret instead of a key pair
+func (x SigningAlgValue) IsSymmetric() bool {
+	return strings.HasPrefix(string(x), "HS")
+}
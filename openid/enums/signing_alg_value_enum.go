@@ -0,0 +1,113 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package enums
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// SigningAlgValueRS256 is a SigningAlgValue of type RS256.
+	SigningAlgValueRS256 SigningAlgValue = "RS256"
+	// SigningAlgValueRS384 is a SigningAlgValue of type RS384.
+	SigningAlgValueRS384 SigningAlgValue = "RS384"
+	// SigningAlgValueRS512 is a SigningAlgValue of type RS512.
+	SigningAlgValueRS512 SigningAlgValue = "RS512"
+	// SigningAlgValuePS256 is a SigningAlgValue of type PS256.
+	SigningAlgValuePS256 SigningAlgValue = "PS256"
+	// SigningAlgValuePS384 is a SigningAlgValue of type PS384.
+	SigningAlgValuePS384 SigningAlgValue = "PS384"
+	// SigningAlgValuePS512 is a SigningAlgValue of type PS512.
+	SigningAlgValuePS512 SigningAlgValue = "PS512"
+	// SigningAlgValueES256 is a SigningAlgValue of type ES256.
+	SigningAlgValueES256 SigningAlgValue = "ES256"
+	// SigningAlgValueES384 is a SigningAlgValue of type ES384.
+	SigningAlgValueES384 SigningAlgValue = "ES384"
+	// SigningAlgValueES512 is a SigningAlgValue of type ES512.
+	SigningAlgValueES512 SigningAlgValue = "ES512"
+	// SigningAlgValueEdDSA is a SigningAlgValue of type EdDSA.
+	SigningAlgValueEdDSA SigningAlgValue = "EdDSA"
+	// SigningAlgValueHS256 is a SigningAlgValue of type HS256.
+	SigningAlgValueHS256 SigningAlgValue = "HS256"
+	// SigningAlgValueHS384 is a SigningAlgValue of type HS384.
+	SigningAlgValueHS384 SigningAlgValue = "HS384"
+	// SigningAlgValueHS512 is a SigningAlgValue of type HS512.
+	SigningAlgValueHS512 SigningAlgValue = "HS512"
+)
+
+var ErrInvalidSigningAlgValue = errors.New("not a valid SigningAlgValue")
+
+// SigningAlgValueValues returns a list of the values for SigningAlgValue
+func SigningAlgValueValues() []SigningAlgValue {
+	return []SigningAlgValue{
+		SigningAlgValueRS256,
+		SigningAlgValueRS384,
+		SigningAlgValueRS512,
+		SigningAlgValuePS256,
+		SigningAlgValuePS384,
+		SigningAlgValuePS512,
+		SigningAlgValueES256,
+		SigningAlgValueES384,
+		SigningAlgValueES512,
+		SigningAlgValueEdDSA,
+		SigningAlgValueHS256,
+		SigningAlgValueHS384,
+		SigningAlgValueHS512,
+	}
+}
+
+// String implements the Stringer interface.
+func (x SigningAlgValue) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x SigningAlgValue) IsValid() bool {
+	_, err := ParseSigningAlgValue(string(x))
+	return err == nil
+}
+
+var _SigningAlgValueValue = map[string]SigningAlgValue{
+	"RS256": SigningAlgValueRS256,
+	"RS384": SigningAlgValueRS384,
+	"RS512": SigningAlgValueRS512,
+	"PS256": SigningAlgValuePS256,
+	"PS384": SigningAlgValuePS384,
+	"PS512": SigningAlgValuePS512,
+	"ES256": SigningAlgValueES256,
+	"ES384": SigningAlgValueES384,
+	"ES512": SigningAlgValueES512,
+	"EdDSA": SigningAlgValueEdDSA,
+	"HS256": SigningAlgValueHS256,
+	"HS384": SigningAlgValueHS384,
+	"HS512": SigningAlgValueHS512,
+}
+
+// ParseSigningAlgValue attempts to convert a string to a SigningAlgValue.
+func ParseSigningAlgValue(name string) (SigningAlgValue, error) {
+	if x, ok := _SigningAlgValueValue[name]; ok {
+		return x, nil
+	}
+	return SigningAlgValue(""), fmt.Errorf("%s is %w", name, ErrInvalidSigningAlgValue)
+}
+
+// MarshalText implements the text marshaller method.
+func (x SigningAlgValue) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *SigningAlgValue) UnmarshalText(text []byte) error {
+	tmp, err := ParseSigningAlgValue(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -1,4 +1,4 @@
 package enums
 
-// ENUM(public)
+// ENUM(public, pairwise)
 type SubjectType string
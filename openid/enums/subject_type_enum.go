@@ -14,6 +14,8 @@ import (
 const (
 	// SubjectTypePublic is a SubjectType of type public.
 	SubjectTypePublic SubjectType = "public"
+	// SubjectTypePairwise is a SubjectType of type pairwise.
+	SubjectTypePairwise SubjectType = "pairwise"
 )
 
 var ErrInvalidSubjectType = errors.New("not a valid SubjectType")
@@ -22,6 +24,7 @@ var ErrInvalidSubjectType = errors.New("not a valid SubjectType")
 func SubjectTypeValues() []SubjectType {
 	return []SubjectType{
 		SubjectTypePublic,
+		SubjectTypePairwise,
 	}
 }
 
@@ -38,7 +41,8 @@ func (x SubjectType) IsValid() bool {
 }
 
 var _SubjectTypeValue = map[string]SubjectType{
-	"public": SubjectTypePublic,
+	"public":   SubjectTypePublic,
+	"pairwise": SubjectTypePairwise,
 }
 
 // ParseSubjectType attempts to convert a string to a SubjectType.
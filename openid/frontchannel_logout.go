@@ -0,0 +1,76 @@
+package openid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/web"
+)
+
+// finishLogout ends the token sessions and the browser session of the user.
+// Clients with a front-channel logout uri are loaded in iframes before the user is redirected.
+func (ip *IdentitiyProvider) finishLogout(w http.ResponseWriter, r *http.Request, sessionID, redirect string) {
+	ctx := r.Context()
+
+	// The browser session has to be read before it is destroyed
+	frontchannelLogoutURIs := ip.frontchannelLogoutURIs(ctx)
+	ip.logoutSessions(ctx, ip.browserSessionIDs(ctx, sessionID))
+
+	if err := ip.sessionManager.Destroy(ctx); err != nil {
+		// TODO: Better Logging
+		fmt.Println("session destroy did not work", err)
+	}
+
+	if len(frontchannelLogoutURIs) == 0 && redirect != "" {
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	web.LoggedOut(frontchannelLogoutURIs, redirect).Render(ctx, w)
+}
+
+// browserSessionIDs returns the sids of the clients which got tokens in the browser session
+// and the sid of the id_token_hint
+func (ip *IdentitiyProvider) browserSessionIDs(ctx context.Context, sessionID string) []string {
+	var sessionIDs []string
+	if sessionID != "" {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	for _, clientSession := range ip.sessionManager.GetClientSessions(ctx) {
+		if clientSession.SessionID != "" && !slices.Contains(sessionIDs, clientSession.SessionID) {
+			sessionIDs = append(sessionIDs, clientSession.SessionID)
+		}
+	}
+	return sessionIDs
+}
+
+// frontchannelLogoutURIs returns the logout uris of the clients which got tokens in the browser session
+func (ip *IdentitiyProvider) frontchannelLogoutURIs(ctx context.Context) []string {
+	j := jose.GetJose(ctx)
+
+	var logoutURIs []string
+	for _, clientSession := range ip.sessionManager.GetClientSessions(ctx) {
+		reg, err := ip.clientStore.GetClient(ctx, clientSession.ClientID)
+		if err != nil || reg.FrontchannelLogoutURI == "" {
+			continue
+		}
+
+		logoutURI, err := url.Parse(reg.FrontchannelLogoutURI)
+		if err != nil {
+			continue
+		}
+		query := logoutURI.Query()
+		query.Set("iss", j.Issuer())
+		query.Set("sid", clientSession.SessionID)
+		logoutURI.RawQuery = query.Encode()
+
+		logoutURIs = append(logoutURIs, logoutURI.String())
+	}
+	return logoutURIs
+}
@@ -0,0 +1,131 @@
+package openid
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	usermemorystore "github.com/akatranlp/sentinel/account/memory_store"
+	"github.com/akatranlp/sentinel/jose"
+	sessionmemorystore "github.com/akatranlp/sentinel/session/memory_store"
+	tokenmemorystore "github.com/akatranlp/sentinel/token/memory_store"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+const testOrigin = "http://localhost"
+
+// newTestKey creates a signing key with kid and alg, like the keys of the key file
+func newTestKey(t *testing.T) jwk.Key {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwk.Import(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = jwk.AssignKeyID(key); err != nil {
+		t.Fatal(err)
+	}
+	if err = key.Set(jwk.AlgorithmKey, jwa.ES256()); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// newTestSigningKey creates a key for the provider, which signs its tokens with RS256
+func newTestSigningKey(t *testing.T) jwk.Key {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwk.Import(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = jwk.AssignKeyID(key); err != nil {
+		t.Fatal(err)
+	}
+	if err = key.Set(jwk.AlgorithmKey, jwa.RS256()); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// newTestPublicKeySet returns the public keys of the keys as set, like a client registers them
+func newTestPublicKeySet(t *testing.T, keys ...jwk.Key) jwk.Set {
+	t.Helper()
+	set := jwk.NewSet()
+	for _, key := range keys {
+		publicKey, err := key.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = set.AddKey(publicKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return set
+}
+
+// newTestProvider creates an identity provider with in-memory stores
+func newTestProvider(t *testing.T, opts ...OptionFn) *IdentitiyProvider {
+	t.Helper()
+	ip, err := tryNewTestProvider(t, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ip
+}
+
+// tryNewTestProvider creates an identity provider like newTestProvider, but returns the error of the options
+func tryNewTestProvider(t *testing.T, opts ...OptionFn) (*IdentitiyProvider, error) {
+	t.Helper()
+	userStore, err := usermemorystore.NewMemoryUserStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenStore, err := tokenmemorystore.NewMemoryTokenStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionStore, err := sessionmemorystore.NewWithCleanupInterval(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts = append([]OptionFn{WithSigningKey(newTestSigningKey(t)), WithAccessTokenExpiration(time.Minute)}, opts...)
+	return NewIdentityProvider("/auth", userStore, tokenStore, nil, sessionStore, opts...)
+}
+
+// testContext returns a context with the jose of the test origin, which is set by the middleware of the handler
+func testContext(t *testing.T, ip *IdentitiyProvider) context.Context {
+	t.Helper()
+	j, err := ip.joseBuilder.Build(testOrigin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jose.SetJose(context.Background(), j)
+}
+
+// newTestRequest creates a request to the endpoint with the parsed form
+func newTestRequest(t *testing.T, ip *IdentitiyProvider, method, path string, form url.Values) *http.Request {
+	t.Helper()
+	r := httptest.NewRequestWithContext(testContext(t, ip), method, testOrigin+ip.basePath+path, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := r.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
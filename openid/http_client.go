@@ -0,0 +1,70 @@
+package openid
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const outboundRequestTimeout = 10 * time.Second
+
+var ErrAddressNotAllowed = errors.New("address is not allowed")
+
+// sharedAddressSpace is used by carrier-grade NATs and is not reachable from the internet (RFC 6598)
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// newRestrictedHTTPClient returns the client for urls, which are registered by clients like a jwks_uri.
+// It only connects to public addresses, so a client can not make the server call internal services.
+// The address is checked after the name is resolved and redirects are not followed.
+func newRestrictedHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: outboundRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the target, so the address check would not apply to the target
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   outboundRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// newLogoutHTTPClient returns the client for the back-channel logout.
+// The logout uris of clients can be internal services, but redirects are not followed,
+// so a client can not send the logout request anywhere else.
+func newLogoutHTTPClient() *http.Client {
+	return &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr)
+}
@@ -0,0 +1,58 @@
+package openid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.215.14", want: true},
+		{addr: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.0.0.1"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "224.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPostLogoutTokenDoesNotFollowRedirects(t *testing.T) {
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	logoutServer := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer logoutServer.Close()
+
+	ip := newTestProvider(t)
+	retry, err := ip.postLogoutToken(context.Background(), logoutServer.URL, "logout_token=token")
+	if err == nil || retry {
+		t.Errorf("expected the redirect to be rejected without retry, got retry %t and %v", retry, err)
+	}
+	if redirected {
+		t.Error("expected the redirect not to be followed")
+	}
+}
@@ -1,32 +1,89 @@
 package openid
 
 import (
+	"context"
+	"crypto/x509"
+	"errors"
 	"html/template"
 	"io"
 	"io/fs"
 	"iter"
+	"net/http"
 	"slices"
-	"sync"
 	"time"
 
 	"github.com/akatranlp/sentinel/account"
+	"github.com/akatranlp/sentinel/authcode"
+	authcodememorystore "github.com/akatranlp/sentinel/authcode/memory_store"
+	"github.com/akatranlp/sentinel/client"
+	clientmemorystore "github.com/akatranlp/sentinel/client/memory_store"
+	"github.com/akatranlp/sentinel/consent"
+	consentmemorystore "github.com/akatranlp/sentinel/consent/memory_store"
+	"github.com/akatranlp/sentinel/device"
+	devicememorystore "github.com/akatranlp/sentinel/device/memory_store"
 	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
 	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/par"
+	parmemorystore "github.com/akatranlp/sentinel/par/memory_store"
 	"github.com/akatranlp/sentinel/provider"
+	"github.com/akatranlp/sentinel/resource"
+	resourcememorystore "github.com/akatranlp/sentinel/resource/memory_store"
 	"github.com/akatranlp/sentinel/session"
 	"github.com/akatranlp/sentinel/token"
 	"github.com/akatranlp/sentinel/web"
 	"github.com/alexedwards/scs/v2"
 	"github.com/lestrrat-go/jwx/v3/jwk"
+	"golang.org/x/sync/singleflight"
 )
 
 type ipConfig struct {
 	// Clients
-	clients map[string]ClientRegistration
+	clients           []client.Client
+	clientAuthMethods []enums.EndpointAuthMethod
+	// Dynamic Client Registration is only enabled with initial access tokens
+	initialAccessTokens []string
+	// registrationScopes are granted to registered clients, which do not request scopes
+	registrationScopes enums.Scopes
 
 	// Provider
 	providers map[string]provider.Provider
 
+	// Consent
+	grantStore consent.GrantStore
+
+	// Resource Indicators
+	resources     []resource.Resource
+	resourceStore resource.ResourceStore
+
+	// Account Refresh
+	accountRefreshInterval    time.Duration
+	accountRefreshWindow      time.Duration
+	accountRefreshConcurrency int
+
+	// Errors of the background work
+	errorHandler ErrorFunc
+
+	// Claims
+	scopeClaims map[enums.Scope][]string
+	claimValues ClaimValuesFunc
+
+	// Authorization Codes
+	authCodeStore    authcode.AuthCodeStore
+	authCodeLifeTime time.Duration
+	// authCodeRetention is how long redeemed codes are kept to detect replays
+	authCodeRetention time.Duration
+
+	// Security Events
+	securityEvents SecurityEventFunc
+
+	// Pushed Authorization Requests
+	pushedRequestStore par.PushedRequestStore
+
+	// Device Authorization
+	deviceCodeStore    device.DeviceCodeStore
+	deviceCodeLifeTime time.Duration
+
 	// SessionManager
 	sessionName             string
 	sessionUnAuthedLifeTime time.Duration
@@ -34,12 +91,32 @@ type ipConfig struct {
 	sessionIdleTimout       time.Duration
 
 	// JOSE
-	basePath         string
-	publicURL        string
-	atExpiration     time.Duration
-	rtExpiration     time.Duration
-	signingKey       jwk.Key
-	signingKeyReader io.Reader
+	basePath     string
+	publicURL    string
+	atExpiration time.Duration
+	rtExpiration time.Duration
+	// Access Token Format
+	accessTokenFormat enums.AccessTokenFormat
+	signingKey        jwk.Key
+	signingKeyReader  io.Reader
+	// Pairwise Subject Identifiers
+	pairwiseSubjectSalt []byte
+
+	// Back-Channel Logout
+	backchannelLogoutTimeout time.Duration
+	backchannelLogoutRetries int
+
+	// DPoP
+	dpopNonceLifeTime time.Duration
+
+	// Outbound Requests
+	// outboundHTTPClient fetches the urls, which are registered by clients
+	outboundHTTPClient *http.Client
+
+	// TLS
+	tlsCertFile  string
+	tlsKeyFile   string
+	tlsClientCAs *x509.CertPool
 
 	// Web
 	appURL     string
@@ -48,16 +125,32 @@ type ipConfig struct {
 }
 
 var defaultConfig = ipConfig{
-	clients:   make(map[string]ClientRegistration),
+	clientAuthMethods:  enums.EndpointAuthMethodValues(),
+	registrationScopes: enums.Scopes{enums.ScopeOpenid},
+
 	providers: make(map[string]provider.Provider),
-	basePath:  "/",
-	publicURL: "",
+
+	scopeClaims:       defaultScopeClaims,
+	basePath:          "/",
+	publicURL:         "",
+	accessTokenFormat: enums.AccessTokenFormatLegacy,
 
 	sessionName:             "auth-session",
 	sessionUnAuthedLifeTime: 30 * time.Minute,
 	sessionAuthedLifeTime:   364 * 24 * time.Hour,
 	sessionIdleTimout:       7 * 24 * time.Hour,
 
+	authCodeLifeTime:   10 * time.Second,
+	authCodeRetention:  time.Hour,
+	deviceCodeLifeTime: 10 * time.Minute,
+
+	accountRefreshInterval:    time.Minute,
+	accountRefreshWindow:      5 * time.Minute,
+	accountRefreshConcurrency: 4,
+
+	backchannelLogoutTimeout: 5 * time.Second,
+	backchannelLogoutRetries: 3,
+
 	templateFS: web.TemplateFS,
 	assetFS:    web.AssetFS,
 }
@@ -67,15 +160,23 @@ type IdentitiyProvider struct {
 	joseBuilder    *jose.JoseBuilder
 	userStore      account.UserStore
 	tokenStore     token.TokenStore
+	clientStore    client.ClientStore
 	sessionManager *session.SessionManager
-	authMap        sync.Map
-	templates      *template.Template
+	// accountRefreshes coalesces the concurrent refreshes of an upstream account
+	accountRefreshes singleflight.Group
+	clientKeySets    *clientKeySetCache
+	// logoutHTTPClient sends the back-channel logout tokens without following redirects
+	logoutHTTPClient *http.Client
+	replayCache      *replayCache
+	dpopNonce        *dpopNonce
+	templates        *template.Template
 }
 
 func NewIdentityProvider(
 	basePath string,
 	userStore account.UserStore,
 	tokenStore token.TokenStore,
+	clientStore client.ClientStore,
 	sessionStore scs.Store,
 	opts ...OptionFn,
 ) (*IdentitiyProvider, error) {
@@ -88,6 +189,74 @@ func NewIdentityProvider(
 		}
 	}
 
+	// Mutual-TLS authentication needs the client certificate, which is only requested when we serve TLS
+	if conf.tlsCertFile == "" {
+		conf.clientAuthMethods = slices.DeleteFunc(slices.Clone(conf.clientAuthMethods), isTLSClientAuthMethod)
+	}
+
+	// Without a secret salt the pairwise subjects could be calculated from the user ids
+	if len(conf.pairwiseSubjectSalt) == 0 && slices.ContainsFunc(conf.clients, func(c client.Client) bool {
+		return c.SubjectType == enums.SubjectTypePairwise
+	}) {
+		return nil, errors.New("pairwise clients require a pairwise subject salt")
+	}
+
+	if clientStore == nil {
+		clientStore, err = clientmemorystore.NewMemoryClientStore()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range conf.clients {
+		if err = clientStore.SetClient(context.Background(), c); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.grantStore == nil {
+		conf.grantStore, err = consentmemorystore.NewMemoryGrantStore()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.resourceStore == nil {
+		conf.resourceStore, err = resourcememorystore.NewMemoryResourceStore()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, res := range conf.resources {
+		if err = conf.resourceStore.SetResource(context.Background(), res); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.authCodeStore == nil {
+		conf.authCodeStore, err = authcodememorystore.NewMemoryAuthCodeStore()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.outboundHTTPClient == nil {
+		conf.outboundHTTPClient = newRestrictedHTTPClient()
+	}
+
+	if conf.pushedRequestStore == nil {
+		conf.pushedRequestStore, err = parmemorystore.NewMemoryPushedRequestStore()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.deviceCodeStore == nil {
+		conf.deviceCodeStore, err = devicememorystore.NewMemoryDeviceCodeStore()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	templates, err := template.New("templates").Funcs(template.FuncMap{
 		"toJSDeclaration": types.ToJsDeclaration,
 		"iterValue": func() iter.Seq[types.TokenResponseField] {
@@ -103,6 +272,8 @@ func NewIdentityProvider(
 		jose.WithPublicURL(conf.publicURL),
 		jose.WithAccessTokenExpiration(conf.atExpiration),
 		jose.WithRefeshTokenExpiration(conf.rtExpiration),
+		jose.WithPairwiseSalt(conf.pairwiseSubjectSalt),
+		jose.WithAccessTokenFormat(conf.accessTokenFormat),
 	}
 	if conf.signingKey != nil {
 		joseOpts = append(joseOpts, jose.WithSigningKey(conf.signingKey))
@@ -130,14 +301,23 @@ func NewIdentityProvider(
 		session.WithIdleTimeout(conf.sessionIdleTimout),
 	)
 
+	var nonce *dpopNonce
+	if conf.dpopNonceLifeTime > 0 {
+		nonce = newDPoPNonce(conf.dpopNonceLifeTime)
+	}
+
 	return &IdentitiyProvider{
-		ipConfig:       conf,
-		joseBuilder:    jb,
-		userStore:      userStore,
-		tokenStore:     tokenStore,
-		sessionManager: sm,
-		authMap:        sync.Map{},
-		templates:      templates,
+		ipConfig:         conf,
+		joseBuilder:      jb,
+		userStore:        userStore,
+		tokenStore:       tokenStore,
+		clientStore:      clientStore,
+		sessionManager:   sm,
+		clientKeySets:    &clientKeySetCache{client: conf.outboundHTTPClient},
+		logoutHTTPClient: newLogoutHTTPClient(),
+		replayCache:      newReplayCache(),
+		dpopNonce:        nonce,
+		templates:        templates,
 	}, nil
 }
 
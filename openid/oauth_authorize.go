@@ -4,6 +4,7 @@ import (
 	"maps"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/a-h/templ"
@@ -13,6 +14,7 @@ import (
 	"github.com/akatranlp/sentinel/utils"
 
 	"github.com/go-viper/mapstructure/v2"
+	"github.com/google/uuid"
 )
 
 func (ip *IdentitiyProvider) OauthAuthorize(w http.ResponseWriter, r *http.Request) {
@@ -28,56 +30,95 @@ func (ip *IdentitiyProvider) OauthAuthorize(w http.ResponseWriter, r *http.Reque
 	}
 
 	clientID := r.FormValue(AuthorizeFormValueClientId.String())
-	redirectURI, err := url.ParseRequestURI(r.FormValue(AuthorizeFormValueRedirectUri.String()))
+	reg, err := ip.clientStore.GetClient(r.Context(), clientID)
 	if err != nil {
-		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	reg, ok := ip.clients[clientID]
-	if !ok {
 		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "invalid client id", http.StatusBadRequest)
 		return
 	}
 
-	if err = reg.CheckRedirectURI(*redirectURI); err != nil {
-		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "invalid redirect uri", http.StatusBadRequest)
+	if r.Form.Has(AuthorizeRequestFormValueRequest.String()) && r.Form.Has(AuthorizeRequestFormValueRequestUri.String()) {
+		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "request and request_uri cannot be used together", http.StatusBadRequest)
 		return
 	}
 
 	var formValues types.AuthRequest
-	config := utils.CreateDecoderConfig()
-	config.Result = &formValues
-	decoder, err := mapstructure.NewDecoder(&config)
-	if err != nil {
-		handleClientError(w, r, formValues, AuthorizeError{
-			AuthorizeErrorTypeInvalidRequest,
-			err.Error(),
-		})
-		return
-	}
+	if requestURI := r.FormValue(AuthorizeRequestFormValueRequestUri.String()); requestURI != "" {
+		// We do not fetch request objects from the outside, only pushed requests are supported
+		if !strings.HasPrefix(requestURI, requestURIPrefix) {
+			sendErrorPage(w, r, AuthorizeErrorTypeRequestUriNotSupported.String(), "only request uris of the par endpoint are supported", http.StatusBadRequest)
+			return
+		}
 
-	params, err := mapFormValueKeys(r.Form, maps.Keys(_AuthorizeFormValueValue))
-	if err != nil {
-		handleClientError(w, r, formValues, AuthorizeError{
-			AuthorizeErrorTypeInvalidRequest,
-			err.Error(),
-		})
-		return
-	}
+		// The pushed request was already validated at the par endpoint
+		var ok bool
+		formValues, ok = ip.validateRequestURI(r.Context(), requestURI, clientID)
+		if !ok {
+			sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequestUri.String(), "invalid or expired request uri", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if reg.RequirePushedAuthorizationRequests {
+			sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "pushed authorization request required", http.StatusBadRequest)
+			return
+		}
 
-	if err = decoder.Decode(params); err != nil {
-		handleClientError(w, r, formValues, AuthorizeError{
-			AuthorizeErrorTypeInvalidRequest,
-			err.Error(),
-		})
-		return
+		if r.Form.Has(AuthorizeRequestFormValueRequest.String()) {
+			form, err := ip.resolveRequestObject(r.Context(), reg, r.Form)
+			if err != nil {
+				sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequestObject.String(), err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Form = form
+		}
+
+		redirectURI, err := url.ParseRequestURI(r.FormValue(AuthorizeFormValueRedirectUri.String()))
+		if err != nil {
+			sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = reg.CheckRedirectURI(*redirectURI); err != nil {
+			sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "invalid redirect uri", http.StatusBadRequest)
+			return
+		}
+
+		formValues, err = decodeAuthRequest(r.Form)
+		if err != nil {
+			handleClientError(w, r, formValues, AuthorizeError{
+				AuthorizeErrorTypeInvalidRequest,
+				err.Error(),
+			})
+			return
+		}
+
+		if err = formValues.IsValid(); err != nil {
+			handleClientError(w, r, formValues, AuthorizeError{
+				AuthorizeErrorTypeInvalidRequest,
+				err.Error(),
+			})
+			return
+		}
+		if !ip.supportsScopes(formValues.Scope) {
+			handleClientError(w, r, formValues, AuthorizeError{
+				AuthorizeErrorTypeInvalidScope,
+				enums.ErrInvalidScope.Error(),
+			})
+			return
+		}
+
+		if _, err = ip.lookupResources(r.Context(), clientID, formValues.Resource); err != nil {
+			handleClientError(w, r, formValues, AuthorizeError{
+				AuthorizeErrorTypeInvalidTarget,
+				err.Error(),
+			})
+			return
+		}
 	}
 
-	if err = formValues.IsValid(); err != nil {
+	if !reg.CheckGrantType(enums.GrantTypeAuthorizationCode) {
 		handleClientError(w, r, formValues, AuthorizeError{
-			AuthorizeErrorTypeInvalidRequest,
-			err.Error(),
+			AuthorizeErrorTypeUnauthorizedClient,
+			"the client is not allowed to use this grant type",
 		})
 		return
 	}
@@ -92,6 +133,8 @@ func (ip *IdentitiyProvider) OauthAuthorize(w http.ResponseWriter, r *http.Reque
 		ip.sessionManager.SetAuth(ctx, "")
 	} else if isAuthed && formValues.Prompt == enums.PromptLogin {
 		ip.sessionManager.SetAuth(ctx, "")
+	} else if isAuthed && ip.requiresStepUp(ctx, formValues) {
+		ip.sessionManager.SetAuth(ctx, "")
 	}
 
 	// We need to ask the session again because the previous check could have logged out the user
@@ -109,11 +152,31 @@ func (ip *IdentitiyProvider) OauthAuthorize(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	ip.sendAuthResponse(w, r, AuthTokenValues{
-		AuthRequest: formValues,
-		UserID:      userID,
-		AuthTime:    authTime,
-	})
+	ip.continueAuthorization(w, r, formValues)
+}
+
+// decodeAuthRequest decodes the authorization parameters of the form.
+// The returned request can be partially filled if an error occurs.
+func decodeAuthRequest(form url.Values) (types.AuthRequest, error) {
+	var formValues types.AuthRequest
+	config := utils.CreateDecoderConfig()
+	config.Result = &formValues
+	decoder, err := mapstructure.NewDecoder(&config)
+	if err != nil {
+		return formValues, err
+	}
+
+	params, err := mapFormValueKeys(form, maps.Keys(_AuthorizeFormValueValue))
+	if err != nil {
+		return formValues, err
+	}
+
+	if err = decoder.Decode(params); err != nil {
+		return formValues, err
+	}
+	formValues.Resource = form[resourceParam]
+
+	return formValues, nil
 }
 
 func (ip *IdentitiyProvider) sendAuthResponse(w http.ResponseWriter, r *http.Request, formValues AuthTokenValues, flashMessages ...templ.Component) {
@@ -125,10 +188,23 @@ func (ip *IdentitiyProvider) sendAuthResponse(w http.ResponseWriter, r *http.Req
 
 	switch formValues.ResponseType {
 	case enums.ResponseTypeCode:
-		code := ip.createAuthToken(formValues)
+		formValues.SessionID = uuid.NewString()
+		ip.sessionManager.AddClientSession(r.Context(), types.ClientSession{
+			ClientID:  formValues.ClientID,
+			SessionID: formValues.SessionID,
+		})
+		code, err := ip.createAuthToken(r.Context(), formValues)
+		if err != nil {
+			handleClientError(w, r, formValues.AuthRequest, AuthorizeError{AuthorizeErrorTypeServerError, err.Error()})
+			return
+		}
 		params.Set("code", code)
 	}
 
+	if browserState := ip.sessionManager.GetBrowserState(r.Context()); browserState != "" {
+		params.Set("session_state", sessionState(formValues.ClientID, redirectURL, browserState))
+	}
+
 	switch formValues.ResponseMode {
 	case enums.ResponseModeFormPost:
 		w.Header().Set("Cache-Control", "no-store")
@@ -195,15 +271,27 @@ func handleClientError(w http.ResponseWriter, r *http.Request, formValues types.
 // nonce,
 // code_challenge,
 // code_challenge_method,
+// claims,
+// acr_values,
+// prompt,
+// max_age,
+// display,
 // )
 type AuthorizeFormValue string
 
+// These parameters reference the authorization request instead of being part of it
+// ENUM(
+// request,
+// request_uri,
+// )
+type AuthorizeRequestFormValue string
+
 // ENUM(
 //
 //	// These are standard OAUTH 2.0 errorTypes
 //	invalid_request, unauthorized_client, access_denied, unsupported_response_type, invalid_scope, server_error
 //	// OpenID Connect ErrorTypes
-//	interaction_required, login_required, account_selection_required, consent_required, invalid_request_uri, invalid_request_object, requets_not_supported, request_uri_not_supported, registration_not_supported
+//	interaction_required, login_required, account_selection_required, consent_required, invalid_request_uri, invalid_request_object, requets_not_supported, request_uri_not_supported, registration_not_supported, unmet_authentication_requirements, invalid_target
 //
 // )
 type AuthorizeErrorType string
@@ -42,6 +42,10 @@ const (
 	AuthorizeErrorTypeRequestUriNotSupported AuthorizeErrorType = "request_uri_not_supported"
 	// AuthorizeErrorTypeRegistrationNotSupported is a AuthorizeErrorType of type registration_not_supported.
 	AuthorizeErrorTypeRegistrationNotSupported AuthorizeErrorType = "registration_not_supported"
+	// AuthorizeErrorTypeUnmetAuthenticationRequirements is a AuthorizeErrorType of type unmet_authentication_requirements.
+	AuthorizeErrorTypeUnmetAuthenticationRequirements AuthorizeErrorType = "unmet_authentication_requirements"
+	// AuthorizeErrorTypeInvalidTarget is a AuthorizeErrorType of type invalid_target.
+	AuthorizeErrorTypeInvalidTarget AuthorizeErrorType = "invalid_target"
 )
 
 var ErrInvalidAuthorizeErrorType = errors.New("not a valid AuthorizeErrorType")
@@ -59,21 +63,23 @@ func (x AuthorizeErrorType) IsValid() bool {
 }
 
 var _AuthorizeErrorTypeValue = map[string]AuthorizeErrorType{
-	"invalid_request":            AuthorizeErrorTypeInvalidRequest,
-	"unauthorized_client":        AuthorizeErrorTypeUnauthorizedClient,
-	"access_denied":              AuthorizeErrorTypeAccessDenied,
-	"unsupported_response_type":  AuthorizeErrorTypeUnsupportedResponseType,
-	"invalid_scope":              AuthorizeErrorTypeInvalidScope,
-	"server_error":               AuthorizeErrorTypeServerError,
-	"interaction_required":       AuthorizeErrorTypeInteractionRequired,
-	"login_required":             AuthorizeErrorTypeLoginRequired,
-	"account_selection_required": AuthorizeErrorTypeAccountSelectionRequired,
-	"consent_required":           AuthorizeErrorTypeConsentRequired,
-	"invalid_request_uri":        AuthorizeErrorTypeInvalidRequestUri,
-	"invalid_request_object":     AuthorizeErrorTypeInvalidRequestObject,
-	"requets_not_supported":      AuthorizeErrorTypeRequetsNotSupported,
-	"request_uri_not_supported":  AuthorizeErrorTypeRequestUriNotSupported,
-	"registration_not_supported": AuthorizeErrorTypeRegistrationNotSupported,
+	"invalid_request":                   AuthorizeErrorTypeInvalidRequest,
+	"unauthorized_client":               AuthorizeErrorTypeUnauthorizedClient,
+	"access_denied":                     AuthorizeErrorTypeAccessDenied,
+	"unsupported_response_type":         AuthorizeErrorTypeUnsupportedResponseType,
+	"invalid_scope":                     AuthorizeErrorTypeInvalidScope,
+	"server_error":                      AuthorizeErrorTypeServerError,
+	"interaction_required":              AuthorizeErrorTypeInteractionRequired,
+	"login_required":                    AuthorizeErrorTypeLoginRequired,
+	"account_selection_required":        AuthorizeErrorTypeAccountSelectionRequired,
+	"consent_required":                  AuthorizeErrorTypeConsentRequired,
+	"invalid_request_uri":               AuthorizeErrorTypeInvalidRequestUri,
+	"invalid_request_object":            AuthorizeErrorTypeInvalidRequestObject,
+	"requets_not_supported":             AuthorizeErrorTypeRequetsNotSupported,
+	"request_uri_not_supported":         AuthorizeErrorTypeRequestUriNotSupported,
+	"registration_not_supported":        AuthorizeErrorTypeRegistrationNotSupported,
+	"unmet_authentication_requirements": AuthorizeErrorTypeUnmetAuthenticationRequirements,
+	"invalid_target":                    AuthorizeErrorTypeInvalidTarget,
 }
 
 // ParseAuthorizeErrorType attempts to convert a string to a AuthorizeErrorType.
@@ -118,6 +124,16 @@ const (
 	AuthorizeFormValueCodeChallenge AuthorizeFormValue = "code_challenge"
 	// AuthorizeFormValueCodeChallengeMethod is a AuthorizeFormValue of type code_challenge_method.
 	AuthorizeFormValueCodeChallengeMethod AuthorizeFormValue = "code_challenge_method"
+	// AuthorizeFormValueClaims is a AuthorizeFormValue of type claims.
+	AuthorizeFormValueClaims AuthorizeFormValue = "claims"
+	// AuthorizeFormValueAcrValues is a AuthorizeFormValue of type acr_values.
+	AuthorizeFormValueAcrValues AuthorizeFormValue = "acr_values"
+	// AuthorizeFormValuePrompt is a AuthorizeFormValue of type prompt.
+	AuthorizeFormValuePrompt AuthorizeFormValue = "prompt"
+	// AuthorizeFormValueMaxAge is a AuthorizeFormValue of type max_age.
+	AuthorizeFormValueMaxAge AuthorizeFormValue = "max_age"
+	// AuthorizeFormValueDisplay is a AuthorizeFormValue of type display.
+	AuthorizeFormValueDisplay AuthorizeFormValue = "display"
 )
 
 var ErrInvalidAuthorizeFormValue = errors.New("not a valid AuthorizeFormValue")
@@ -144,6 +160,11 @@ var _AuthorizeFormValueValue = map[string]AuthorizeFormValue{
 	"nonce":                 AuthorizeFormValueNonce,
 	"code_challenge":        AuthorizeFormValueCodeChallenge,
 	"code_challenge_method": AuthorizeFormValueCodeChallengeMethod,
+	"claims":                AuthorizeFormValueClaims,
+	"acr_values":            AuthorizeFormValueAcrValues,
+	"prompt":                AuthorizeFormValuePrompt,
+	"max_age":               AuthorizeFormValueMaxAge,
+	"display":               AuthorizeFormValueDisplay,
 }
 
 // ParseAuthorizeFormValue attempts to convert a string to a AuthorizeFormValue.
@@ -168,3 +189,52 @@ func (x *AuthorizeFormValue) UnmarshalText(text []byte) error {
 	*x = tmp
 	return nil
 }
+
+const (
+	// AuthorizeRequestFormValueRequest is a AuthorizeRequestFormValue of type request.
+	AuthorizeRequestFormValueRequest AuthorizeRequestFormValue = "request"
+	// AuthorizeRequestFormValueRequestUri is a AuthorizeRequestFormValue of type request_uri.
+	AuthorizeRequestFormValueRequestUri AuthorizeRequestFormValue = "request_uri"
+)
+
+var ErrInvalidAuthorizeRequestFormValue = errors.New("not a valid AuthorizeRequestFormValue")
+
+// String implements the Stringer interface.
+func (x AuthorizeRequestFormValue) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x AuthorizeRequestFormValue) IsValid() bool {
+	_, err := ParseAuthorizeRequestFormValue(string(x))
+	return err == nil
+}
+
+var _AuthorizeRequestFormValueValue = map[string]AuthorizeRequestFormValue{
+	"request":     AuthorizeRequestFormValueRequest,
+	"request_uri": AuthorizeRequestFormValueRequestUri,
+}
+
+// ParseAuthorizeRequestFormValue attempts to convert a string to a AuthorizeRequestFormValue.
+func ParseAuthorizeRequestFormValue(name string) (AuthorizeRequestFormValue, error) {
+	if x, ok := _AuthorizeRequestFormValueValue[name]; ok {
+		return x, nil
+	}
+	return AuthorizeRequestFormValue(""), fmt.Errorf("%s is %w", name, ErrInvalidAuthorizeRequestFormValue)
+}
+
+// MarshalText implements the text marshaller method.
+func (x AuthorizeRequestFormValue) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *AuthorizeRequestFormValue) UnmarshalText(text []byte) error {
+	tmp, err := ParseAuthorizeRequestFormValue(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
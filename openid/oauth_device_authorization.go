@@ -0,0 +1,248 @@
+package openid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/go-pkg/its"
+	"github.com/akatranlp/sentinel/device"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/utils"
+	"github.com/go-viper/mapstructure/v2"
+	"golang.org/x/oauth2"
+)
+
+const (
+	userCodeAlphabet   = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength     = 8
+	deviceCodeInterval = 5 * time.Second
+)
+
+func (ip *IdentitiyProvider) OauthDeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	reg, _, tokenErr := ip.authenticateClient(r)
+	if tokenErr != nil {
+		ip.handleTokenError(w, r, tokenErr)
+		return
+	}
+
+	var formValues types.DeviceAuthorizationRequest
+	config := utils.CreateDecoderConfig()
+	config.Result = &formValues
+	decoder, err := mapstructure.NewDecoder(&config)
+	if err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	params, err := mapFormValueKeys(r.Form, maps.Keys(_DeviceAuthorizationFormValueValue))
+	if err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if err := decoder.Decode(params); err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if !reg.CheckGrantType(enums.GrantTypeDeviceCode) {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeUnauthorizedClient,
+			ErrorDescription: "the client is not allowed to use this grant type",
+		})
+		return
+	}
+
+	if !its.All(slices.Values(formValues.Scope), func(scope enums.Scope) bool { return slices.Contains(reg.Scope, scope) }) {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType: TokenErrorTypeInvalidScope,
+		})
+		return
+	}
+
+	ctx := r.Context()
+	userCode, err := ip.createUserCode(ctx)
+	if err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	code := device.DeviceCode{
+		DeviceCode: oauth2.GenerateVerifier(),
+		UserCode:   userCode,
+		ClientID:   reg.ClientID,
+		Scope:      formValues.Scope,
+		Status:     device.StatusPending,
+		Interval:   deviceCodeInterval,
+		Expiry:     time.Now().Add(ip.deviceCodeLifeTime),
+	}
+	if err := ip.deviceCodeStore.CreateDeviceCode(ctx, code); err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	j := jose.GetJose(ctx)
+	verificationURI := j.Issuer() + "/device"
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.DeviceAuthorizationResponse{
+		DeviceCode:              code.DeviceCode,
+		UserCode:                code.UserCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?" + url.Values{"user_code": {code.UserCode}}.Encode(),
+		ExpiresIn:               int(ip.deviceCodeLifeTime / time.Second),
+		Interval:                int(code.Interval / time.Second),
+	})
+}
+
+func (ip *IdentitiyProvider) handleDeviceCode(ctx context.Context, req types.TokenRequest) (types.TokenResponse, *TokenError) {
+	code, err := ip.deviceCodeStore.GetDeviceCode(ctx, req.DeviceCode)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorDescription: err.Error(),
+		}
+	}
+	if code.ClientID != req.ClientID {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorDescription: "clientID does not match with initial device authorization request",
+		}
+	}
+
+	currTime := time.Now()
+	if code.Expiry.Before(currTime) {
+		ip.deviceCodeStore.DeleteDeviceCode(ctx, code.DeviceCode)
+		return types.TokenResponse{}, &TokenError{
+			ErrorType: TokenErrorTypeExpiredToken,
+		}
+	}
+
+	switch code.Status {
+	case device.StatusApproved:
+		// The code can only be redeemed once, so whoever deletes it first gets the tokens
+		if err := ip.deviceCodeStore.DeleteDeviceCode(ctx, code.DeviceCode); err != nil {
+			return types.TokenResponse{}, &TokenError{
+				ErrorType:        TokenErrorTypeInvalidGrant,
+				ErrorDescription: err.Error(),
+			}
+		}
+		return ip.createUserTokens(ctx, AuthTokenValues{
+			AuthRequest: types.AuthRequest{
+				ClientID: code.ClientID,
+				Scope:    code.Scope,
+			},
+			UserID:   code.UserID,
+			AuthTime: code.AuthTime,
+		}, req)
+
+	case device.StatusDenied:
+		ip.deviceCodeStore.DeleteDeviceCode(ctx, code.DeviceCode)
+		return types.TokenResponse{}, &TokenError{
+			ErrorType: TokenErrorTypeAccessDenied,
+		}
+
+	default:
+		errorType := TokenErrorTypeAuthorizationPending
+		interval := code.Interval
+		if currTime.Sub(code.LastPoll) < interval {
+			// RFC 8628 section 3.5: the interval must be increased by 5 seconds for all subsequent requests
+			interval += deviceCodeInterval
+			errorType = TokenErrorTypeSlowDown
+		}
+		// Only the polling state is written, so an approval of the user in the meantime is kept
+		if err := ip.deviceCodeStore.UpdateDeviceCodePoll(ctx, code.DeviceCode, currTime, interval); err != nil {
+			return types.TokenResponse{}, &TokenError{
+				ErrorType:        TokenErrorTypeServerError,
+				ErrorDescription: err.Error(),
+			}
+		}
+		return types.TokenResponse{}, &TokenError{
+			ErrorType: errorType,
+		}
+	}
+}
+
+func (ip *IdentitiyProvider) createUserCode(ctx context.Context) (string, error) {
+	for range 5 {
+		var sb strings.Builder
+		for i := range userCodeLength {
+			if i == userCodeLength/2 {
+				sb.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeAlphabet))))
+			if err != nil {
+				return "", err
+			}
+			sb.WriteByte(userCodeAlphabet[n.Int64()])
+		}
+
+		userCode := sb.String()
+		_, err := ip.deviceCodeStore.GetDeviceCodeByUserCode(ctx, userCode)
+		if errors.Is(err, device.ErrDeviceCodeNotFound) {
+			return userCode, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", errors.New("could not generate a unique user code")
+}
+
+// normalizeUserCode removes everything the user could have typed additionally
+// and brings the code back into the format we have sent to the device
+func normalizeUserCode(userCode string) string {
+	userCode = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(userCodeAlphabet, r) {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(userCode))
+
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+// ENUM(
+// client_id,
+// client_secret,
+// scope,
+// )
+type DeviceAuthorizationFormValue string
@@ -0,0 +1,64 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package openid
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// DeviceAuthorizationFormValueClientId is a DeviceAuthorizationFormValue of type client_id.
+	DeviceAuthorizationFormValueClientId DeviceAuthorizationFormValue = "client_id"
+	// DeviceAuthorizationFormValueClientSecret is a DeviceAuthorizationFormValue of type client_secret.
+	DeviceAuthorizationFormValueClientSecret DeviceAuthorizationFormValue = "client_secret"
+	// DeviceAuthorizationFormValueScope is a DeviceAuthorizationFormValue of type scope.
+	DeviceAuthorizationFormValueScope DeviceAuthorizationFormValue = "scope"
+)
+
+var ErrInvalidDeviceAuthorizationFormValue = errors.New("not a valid DeviceAuthorizationFormValue")
+
+// String implements the Stringer interface.
+func (x DeviceAuthorizationFormValue) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x DeviceAuthorizationFormValue) IsValid() bool {
+	_, err := ParseDeviceAuthorizationFormValue(string(x))
+	return err == nil
+}
+
+var _DeviceAuthorizationFormValueValue = map[string]DeviceAuthorizationFormValue{
+	"client_id":     DeviceAuthorizationFormValueClientId,
+	"client_secret": DeviceAuthorizationFormValueClientSecret,
+	"scope":         DeviceAuthorizationFormValueScope,
+}
+
+// ParseDeviceAuthorizationFormValue attempts to convert a string to a DeviceAuthorizationFormValue.
+func ParseDeviceAuthorizationFormValue(name string) (DeviceAuthorizationFormValue, error) {
+	if x, ok := _DeviceAuthorizationFormValueValue[name]; ok {
+		return x, nil
+	}
+	return DeviceAuthorizationFormValue(""), fmt.Errorf("%s is %w", name, ErrInvalidDeviceAuthorizationFormValue)
+}
+
+// MarshalText implements the text marshaller method.
+func (x DeviceAuthorizationFormValue) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *DeviceAuthorizationFormValue) UnmarshalText(text []byte) error {
+	tmp, err := ParseDeviceAuthorizationFormValue(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"maps"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/akatranlp/sentinel/account"
 	"github.com/akatranlp/sentinel/jose"
@@ -27,14 +29,11 @@ func (ip *IdentitiyProvider) OauthIntrospect(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Some oauth clients are sending the client id and secret as basic auth and some as form-values, we allow both
-	clientID := r.FormValue(IntrospectFormValueClientId.String())
-	clientSecret := r.FormValue(IntrospectFormValueClientSecret.String())
-	if clientID == "" && clientSecret == "" {
-		clientID, clientSecret, _ = r.BasicAuth()
+	_, _, tokenErr := ip.authenticateClient(r)
+	if tokenErr != nil {
+		ip.handleTokenError(w, r, tokenErr)
+		return
 	}
-	r.Form.Set(IntrospectFormValueClientId.String(), clientID)
-	r.Form.Set(IntrospectFormValueClientSecret.String(), clientSecret)
 
 	var formValues types.IntrospectRequest
 	if err := mapstructure.Decode(mapFormValueKeysWithoutError(r.Form, maps.Keys(_IntrospectFormValueValue)), &formValues); err != nil {
@@ -47,26 +46,6 @@ func (ip *IdentitiyProvider) OauthIntrospect(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	reg, ok := ip.clients[formValues.ClientID]
-	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]any{
-			"error":             "invalid_client",
-			"error_description": "invalid client id or client secret",
-		})
-		return
-	}
-	if reg.ClientSecret != "" && formValues.ClientSecret != reg.ClientSecret {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]any{
-			"error":             "invalid_client",
-			"error_description": "invalid client id or client secret",
-		})
-		return
-	}
-
 	if formValues.Token == "" {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -85,7 +64,6 @@ func (ip *IdentitiyProvider) OauthIntrospect(w http.ResponseWriter, r *http.Requ
 	token, err := jwt.ParseString(
 		formValues.Token,
 		jwt.WithKeySet(j.PublicKeys()),
-		jwt.WithAudience(formValues.ClientID),
 		jwt.WithIssuer(j.Issuer()),
 	)
 	if err != nil {
@@ -93,18 +71,39 @@ func (ip *IdentitiyProvider) OauthIntrospect(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	userID := utils.Bang(token.Subject())
-	var sessionID string
-	token.Get(enums.ClaimSid.String(), &sessionID)
-
-	if _, err = ip.userStore.GetUserByID(r.Context(), account.UserID(userID)); err != nil {
+	// Clients can introspect their own tokens and resource servers the tokens issued for them
+	clientID := jose.AccessTokenClientID(token)
+	if clientID != formValues.ClientID && !slices.Contains(utils.Bang(token.Audience()), formValues.ClientID) {
 		json.NewEncoder(w).Encode(res)
 		return
 	}
 
-	if _, err = ip.tokenStore.GetSession(r.Context(), sessionID); err != nil {
-		json.NewEncoder(w).Encode(res)
-		return
+	var sessionID string
+	token.Get(enums.ClaimSid.String(), &sessionID)
+
+	if sessionID == "" {
+		// Tokens of the client credentials grant have no session and the client as subject
+		subjectClientID, ok := strings.CutPrefix(utils.Bang(token.Subject()), clientSubjectPrefix)
+		if !ok || subjectClientID != clientID {
+			json.NewEncoder(w).Encode(res)
+			return
+		}
+		if _, err = ip.clientStore.GetClient(r.Context(), subjectClientID); err != nil {
+			json.NewEncoder(w).Encode(res)
+			return
+		}
+	} else {
+		// The subject can be pairwise, so the user is looked up by the session
+		sess, err := ip.tokenSession(r.Context(), token)
+		if err != nil {
+			json.NewEncoder(w).Encode(res)
+			return
+		}
+
+		if _, err = ip.userStore.GetUserByID(r.Context(), account.UserID(sess.UserID)); err != nil {
+			json.NewEncoder(w).Encode(res)
+			return
+		}
 	}
 
 	var buf bytes.Buffer
@@ -117,7 +116,7 @@ func (ip *IdentitiyProvider) OauthIntrospect(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	res.Active = true
-	res.ClientID = formValues.ClientID
+	res.ClientID = clientID
 	json.NewEncoder(w).Encode(res)
 }
 
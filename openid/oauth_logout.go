@@ -1,7 +1,14 @@
 package openid
 
 import (
-	"fmt"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/sentinel/account"
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/openid/web/shared"
 	"maps"
 	"net/http"
 	"net/url"
@@ -11,177 +18,194 @@ import (
 	"github.com/akatranlp/sentinel/openid/types"
 	"github.com/akatranlp/sentinel/openid/web"
 	csrf "github.com/akatranlp/sentinel/session/gorilla_csrf"
-	"github.com/akatranlp/sentinel/utils"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/lestrrat-go/jwx/v3/jwt"
 	"github.com/lestrrat-go/jwx/v3/jwt/openid"
 )
 
 func (ip *IdentitiyProvider) OauthLogout(w http.ResponseWriter, r *http.Request) {
-	// sess, err := c.GetAuthSession(r)
-	// if err != nil {
-	// 	// TODO: Send a nice frontend back
-	// 	http.Error(w, "invalid session", http.StatusBadRequest)
-	// 	return
-	// }
-	var err error
+	ctx := r.Context()
 
+	// The user confirmed the logout request, which was validated before the confirmation page was rendered
 	if r.Method == http.MethodPost && r.FormValue(ip.sessionManager.CsrfFormField()) != "" {
-		redirect := r.FormValue("redirect")
-		sessionID := r.FormValue("sid")
-
-		if sessionID != "" {
-			ip.tokenStore.RevokeSession(r.Context(), sessionID)
-		}
-
-		if err = ip.sessionManager.Destroy(r.Context()); err != nil {
-			// TODO: Better Logging
-			fmt.Println("session destroy did not work", err)
-		}
-
-		if redirect == "" {
-			http.Error(w, "TODO: UNIMPLEMENTED FINISHED LOGOUT PAGE", http.StatusNotImplemented)
+		logout, ok := ip.sessionManager.GetPendingLogout(ctx)
+		if !ok {
+			ip.handleLogoutError(w, r, "no pending logout request", http.StatusBadRequest)
 			return
 		}
-
-		http.Redirect(w, r, redirect, http.StatusFound)
+		ip.finishLogout(w, r, logout.SessionID, logout.Redirect)
 		return
 	}
 
-	if err = r.ParseForm(); err != nil {
-		ip.handleLogoutError("", w, r, err.Error(), http.StatusBadRequest)
+	if err := r.ParseForm(); err != nil {
+		ip.handleLogoutError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	var formValues types.LogoutRequest
-	if err = mapstructure.Decode(mapFormValueKeysWithoutError(r.Form, maps.Keys(_LogoutFormValueValue)), &formValues); err != nil {
-		ip.handleLogoutError("", w, r, err.Error(), http.StatusBadRequest)
+	if err := mapstructure.Decode(mapFormValueKeysWithoutError(r.Form, maps.Keys(_LogoutFormValueValue)), &formValues); err != nil {
+		ip.handleLogoutError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	j := jose.GetJose(r.Context())
+	if locale, ok := selectUILocale(formValues.UILocales); ok {
+		ctx = shared.SetLocale(ctx, locale)
+		r = r.WithContext(ctx)
+	}
 
-	var clientID string
-	var sessionID string
-	var canDirectlyLogout bool
+	logout := types.PendingLogout{
+		ClientID: formValues.ClientID,
+	}
 
+	var subject string
 	if formValues.IDTokenHint != "" {
-		options := []jwt.ParseOption{
-			jwt.WithResetValidators(true),
-			jwt.WithKeySet(j.PublicKeys()),
-			jwt.WithToken(openid.New()),
-			jwt.WithIssuer(j.Issuer()),
+		token, err := ip.parseIDTokenHint(ctx, formValues.IDTokenHint)
+		if err != nil {
+			ip.handleLogoutError(w, r, "invalid id_token_hint: "+err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		if formValues.ClientID != "" {
-			options = append(options, jwt.WithAudience(formValues.ClientID))
+		audience, _ := token.Audience()
+		if len(audience) == 0 {
+			ip.handleLogoutError(w, r, "the id_token_hint has no audience", http.StatusBadRequest)
+			return
 		}
-
-		var token jwt.Token
-		token, err = jwt.ParseString(formValues.IDTokenHint, options...)
-		if err != nil {
-			ip.handleLogoutError("", w, r, err.Error(), http.StatusBadRequest)
+		if logout.ClientID == "" {
+			logout.ClientID = audience[0]
+		} else if !slices.Contains(audience, logout.ClientID) {
+			ip.handleLogoutError(w, r, "the client_id does not match the audience of the id_token_hint", http.StatusBadRequest)
 			return
 		}
 
-		clientID = utils.Bang(token.Audience())[0]
-		token.Get(enums.ClaimSid.String(), &sessionID)
-		canDirectlyLogout = true
-	} else if formValues.ClientID != "" {
-		clientID = formValues.ClientID
-	}
+		token.Get(enums.ClaimSid.String(), &logout.SessionID)
+		subject, _ = token.Subject()
 
-	if clientID == "" {
-		ip.handleLogoutError(sessionID, w, r, "no client id", http.StatusBadRequest)
-		return
+		// Expired id tokens are accepted as long as the session they were issued for is still known
+		if expiry, ok := token.Expiration(); ok && expiry.Before(time.Now()) && !ip.hasLogoutSession(ctx, logout.SessionID) {
+			ip.handleLogoutError(w, r, "the id_token_hint is expired", http.StatusBadRequest)
+			return
+		}
 	}
 
-	reg, ok := ip.clients[clientID]
-	if !ok {
-		ip.handleLogoutError(sessionID, w, r, "no registration", http.StatusBadRequest)
+	if logout.ClientID == "" && formValues.PostLogoutRedirectURI != "" {
+		ip.handleLogoutError(w, r, "post_logout_redirect_uri requires a client_id or an id_token_hint", http.StatusBadRequest)
 		return
 	}
 
-	var postLogoutRedirectURI *url.URL
-	if formValues.PostLogoutRedirectURI != "" {
-		var redirectURI *url.URL
-		redirectURI, err = url.ParseRequestURI(formValues.PostLogoutRedirectURI)
+	var reg client.Client
+	if logout.ClientID != "" {
+		var err error
+		reg, err = ip.clientStore.GetClient(ctx, logout.ClientID)
 		if err != nil {
-			ip.handleLogoutError(sessionID, w, r, err.Error(), http.StatusBadRequest)
+			ip.handleLogoutError(w, r, "invalid client id", http.StatusBadRequest)
 			return
 		}
-
-		if err = reg.CheckPostLogoutRedirectURI(*redirectURI); err != nil {
-			ip.handleLogoutError(sessionID, w, r, "Check redirect uri failed: "+err.Error(), http.StatusBadRequest)
-			return
+		if formValues.PostLogoutRedirectURI != "" {
+			redirectURI, err := url.ParseRequestURI(formValues.PostLogoutRedirectURI)
+			if err != nil {
+				ip.handleLogoutError(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if err = reg.CheckPostLogoutRedirectURI(*redirectURI); err != nil {
+				ip.handleLogoutError(w, r, "Check redirect uri failed: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if formValues.State != "" {
+				params := redirectURI.Query()
+				params.Set("state", formValues.State)
+				redirectURI.RawQuery = params.Encode()
+			}
+			logout.Redirect = redirectURI.String()
 		}
-		postLogoutRedirectURI = redirectURI
 	}
 
-	if postLogoutRedirectURI != nil {
-		if formValues.State != "" {
-			params := postLogoutRedirectURI.Query()
-			params.Set("state", formValues.State)
-			postLogoutRedirectURI.RawQuery = params.Encode()
-		}
+	// Without a browser session there is nothing the user has to confirm
+	if !ip.sessionManager.IsAuthed(ctx) {
+		ip.finishLogout(w, r, logout.SessionID, logout.Redirect)
+		return
 	}
 
-	var redirect string
-	if postLogoutRedirectURI != nil {
-		redirect = postLogoutRedirectURI.String()
+	// The id_token_hint proves that the logout was requested by a client of the logged in user
+	canDirectlyLogout := subject != "" && subject == ip.subjectIdentifier(ctx, reg, ip.sessionManager.GetAuth(ctx))
+	if canDirectlyLogout && formValues.LogoutHint != "" {
+		canDirectlyLogout = ip.matchesLogoutHint(ctx, formValues.LogoutHint)
 	}
 
-	if !ip.sessionManager.IsAuthed(r.Context()) {
-		if postLogoutRedirectURI != nil && canDirectlyLogout {
-			ip.tokenStore.RevokeSession(r.Context(), sessionID)
-			http.Redirect(w, r, postLogoutRedirectURI.String(), http.StatusFound)
-			return
-		} else if canDirectlyLogout {
-			ip.tokenStore.RevokeSession(r.Context(), sessionID)
-			http.Error(w, "TODO: UNIMPLEMENTED FINISHED LOGOUT PAGE", http.StatusNotImplemented)
-			return
-		} else {
-			web.Logout(ip.sessionManager.CsrfFormField(), csrf.Token(r), sessionID, redirect).Render(r.Context(), w)
-			return
-		}
-	}
-
-	if postLogoutRedirectURI != nil && canDirectlyLogout {
-		ip.tokenStore.RevokeSession(r.Context(), sessionID)
-		if err = ip.sessionManager.Destroy(r.Context()); err != nil {
-			// TODO: Better Logging
-			fmt.Println("session destroy did not work", err)
-		}
-		http.Redirect(w, r, postLogoutRedirectURI.String(), http.StatusFound)
-		return
-	} else if canDirectlyLogout {
-		web.Logout(ip.sessionManager.CsrfFormField(), csrf.Token(r), sessionID, redirect).Render(r.Context(), w)
-		return
-	} else {
-		// TODO: Add userinfo to logout page
-		web.Logout(ip.sessionManager.CsrfFormField(), csrf.Token(r), sessionID, redirect).Render(r.Context(), w)
+	if canDirectlyLogout {
+		ip.finishLogout(w, r, logout.SessionID, logout.Redirect)
 		return
 	}
 
-}
+	ip.sessionManager.SetPendingLogout(ctx, logout)
 
-func (ip *IdentitiyProvider) handleLogoutError(sessionID string, w http.ResponseWriter, r *http.Request, errMsg string, statusCode int) {
 	w.Header().Set("Cache-Control", "no-store")
 	w.Header().Set("Pragma", "no-cache")
-	w.WriteHeader(statusCode)
-	csrfToken := csrf.Token(r)
-	_ = csrfToken
-	// redirectURI = ""
-	// Add csrf
-	// Add sessionID
-	fmt.Println("ERROR: MESSAGE: ", errMsg)
-	web.Logout(ip.sessionManager.CsrfFormField(), csrf.Token(r), sessionID, "").Render(r.Context(), w)
-	return
+	// TODO: Add userinfo to logout page
+	var clientName string
+	if reg.ClientID != "" {
+		clientName = clientDisplayName(reg)
+	}
+	web.Logout(ip.sessionManager.CsrfFormField(), csrf.Token(r), clientName).Render(ctx, w)
+}
+
+// parseIDTokenHint verifies that the id token was issued by this provider.
+// The expiry is not validated, because the spec allows expired id tokens as hint.
+func (ip *IdentitiyProvider) parseIDTokenHint(ctx context.Context, idTokenHint string) (jwt.Token, error) {
+	j := jose.GetJose(ctx)
+
+	return jwt.ParseString(idTokenHint,
+		jwt.WithResetValidators(true),
+		jwt.WithKeySet(j.PublicKeys()),
+		jwt.WithToken(openid.New()),
+		jwt.WithIssuer(j.Issuer()),
+	)
+}
+
+// hasLogoutSession checks if the session is still active at the provider or in the browser
+func (ip *IdentitiyProvider) hasLogoutSession(ctx context.Context, sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	if _, err := ip.tokenStore.GetSession(ctx, sessionID); err == nil {
+		return true
+	}
+	return slices.ContainsFunc(ip.sessionManager.GetClientSessions(ctx), func(clientSession types.ClientSession) bool {
+		return clientSession.SessionID == sessionID
+	})
+}
+
+// matchesLogoutHint checks if the logout_hint identifies the logged in user
+func (ip *IdentitiyProvider) matchesLogoutHint(ctx context.Context, logoutHint string) bool {
+	user, err := ip.userStore.GetUserByID(ctx, account.UserID(ip.sessionManager.GetAuth(ctx)))
+	if err != nil {
+		return false
+	}
+	return logoutHint == string(user.UserID) ||
+		logoutHint == user.Username ||
+		strings.EqualFold(logoutHint, user.Email)
+}
+
+// selectUILocale returns the first of the space separated ui_locales which is supported by the pages
+func selectUILocale(uiLocales string) (string, bool) {
+	for _, locale := range strings.Fields(uiLocales) {
+		language, _, _ := strings.Cut(locale, "-")
+		if slices.Contains(types.UILocalesSupported, strings.ToLower(language)) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+func (ip *IdentitiyProvider) handleLogoutError(w http.ResponseWriter, r *http.Request, errMsg string, statusCode int) {
+	sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), errMsg, statusCode)
 }
 
 // ENUM(
 // client_id,
 // id_token_hint,
+// logout_hint,
 // post_logout_redirect_uri,
 // state,
 // )
@@ -16,6 +16,8 @@ const (
 	LogoutFormValueClientId LogoutFormValue = "client_id"
 	// LogoutFormValueIdTokenHint is a LogoutFormValue of type id_token_hint.
 	LogoutFormValueIdTokenHint LogoutFormValue = "id_token_hint"
+	// LogoutFormValueLogoutHint is a LogoutFormValue of type logout_hint.
+	LogoutFormValueLogoutHint LogoutFormValue = "logout_hint"
 	// LogoutFormValuePostLogoutRedirectUri is a LogoutFormValue of type post_logout_redirect_uri.
 	LogoutFormValuePostLogoutRedirectUri LogoutFormValue = "post_logout_redirect_uri"
 	// LogoutFormValueState is a LogoutFormValue of type state.
@@ -39,6 +41,7 @@ func (x LogoutFormValue) IsValid() bool {
 var _LogoutFormValueValue = map[string]LogoutFormValue{
 	"client_id":                LogoutFormValueClientId,
 	"id_token_hint":            LogoutFormValueIdTokenHint,
+	"logout_hint":              LogoutFormValueLogoutHint,
 	"post_logout_redirect_uri": LogoutFormValuePostLogoutRedirectUri,
 	"state":                    LogoutFormValueState,
 }
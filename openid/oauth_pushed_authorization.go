@@ -0,0 +1,154 @@
+package openid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/par"
+	"golang.org/x/oauth2"
+)
+
+const (
+	requestURIPrefix      = "urn:ietf:params:oauth:request_uri:"
+	pushedAuthReqLifeTime = 60 * time.Second
+)
+
+func (ip *IdentitiyProvider) OauthPushedAuthorization(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	reg, _, tokenErr := ip.authenticateClient(r)
+	if tokenErr != nil {
+		ip.handleTokenError(w, r, tokenErr)
+		return
+	}
+
+	// RFC 9126 section 2.1: the request_uri parameter must not be pushed itself
+	if r.Form.Has(AuthorizeRequestFormValueRequestUri.String()) {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: "request_uri is not allowed in a pushed authorization request",
+		})
+		return
+	}
+
+	if r.Form.Has(AuthorizeRequestFormValueRequest.String()) {
+		form, err := ip.resolveRequestObject(r.Context(), reg, r.Form)
+		if err != nil {
+			ip.handleTokenError(w, r, &TokenError{
+				ErrorType:        TokenErrorTypeInvalidRequestObject,
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+		r.Form = form
+	}
+
+	redirectURI, err := url.ParseRequestURI(r.FormValue(AuthorizeFormValueRedirectUri.String()))
+	if err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if err = reg.CheckRedirectURI(*redirectURI); err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	authReq, err := decodeAuthRequest(r.Form)
+	if err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if err = authReq.IsValid(); err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+	if !ip.supportsScopes(authReq.Scope) {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidScope,
+			ErrorDescription: enums.ErrInvalidScope.Error(),
+		})
+		return
+	}
+
+	if _, err = ip.lookupResources(r.Context(), reg.ClientID, authReq.Resource); err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	requestURI, err := ip.createRequestURI(r.Context(), authReq)
+	if err != nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(types.PushedAuthorizationResponse{
+		RequestURI: requestURI,
+		ExpiresIn:  int(pushedAuthReqLifeTime / time.Second),
+	})
+}
+
+func (ip *IdentitiyProvider) createRequestURI(ctx context.Context, authReq types.AuthRequest) (string, error) {
+	requestURI := requestURIPrefix + oauth2.GenerateVerifier()
+	err := ip.pushedRequestStore.CreatePushedRequest(ctx, par.PushedRequest{
+		RequestURI:  requestURI,
+		AuthRequest: authReq,
+		Expiry:      time.Now().Add(pushedAuthReqLifeTime),
+	})
+	if err != nil {
+		return "", err
+	}
+	return requestURI, nil
+}
+
+// validateRequestURI returns the pushed authorization request of the client.
+// A request_uri can only be used once, but only by the client which pushed it.
+func (ip *IdentitiyProvider) validateRequestURI(ctx context.Context, requestURI, clientID string) (types.AuthRequest, bool) {
+	if !strings.HasPrefix(requestURI, requestURIPrefix) {
+		return types.AuthRequest{}, false
+	}
+	pushed, err := ip.pushedRequestStore.GetPushedRequest(ctx, requestURI)
+	if err != nil || pushed.AuthRequest.ClientID != clientID {
+		return types.AuthRequest{}, false
+	}
+	// Whoever deletes the request first can use it
+	if err = ip.pushedRequestStore.DeletePushedRequest(ctx, requestURI); err != nil {
+		return types.AuthRequest{}, false
+	}
+	return pushed.AuthRequest, true
+}
@@ -0,0 +1,28 @@
+package openid
+
+import (
+	"testing"
+
+	"github.com/akatranlp/sentinel/openid/types"
+)
+
+func TestValidateRequestURI(t *testing.T) {
+	ip := newTestProvider(t)
+	ctx := testContext(t, ip)
+
+	requestURI, err := ip.createRequestURI(ctx, types.AuthRequest{ClientID: "client"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another client must not be able to burn the pushed request
+	if _, ok := ip.validateRequestURI(ctx, requestURI, "other"); ok {
+		t.Fatal("expected the request_uri of another client to be rejected")
+	}
+	if _, ok := ip.validateRequestURI(ctx, requestURI, "client"); !ok {
+		t.Fatal("expected the client to use its request_uri")
+	}
+	if _, ok := ip.validateRequestURI(ctx, requestURI, "client"); ok {
+		t.Fatal("expected the request_uri to be used only once")
+	}
+}
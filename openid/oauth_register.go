@@ -0,0 +1,530 @@
+package openid
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"golang.org/x/oauth2"
+)
+
+const (
+	sectorIdentifierTimeout = 5 * time.Second
+	maxSectorIdentifierSize = 1 << 20
+	// maxRegistrationRequestSize limits the metadata of a client, which can contain a jwks
+	maxRegistrationRequestSize = 1 << 20
+)
+
+// OauthRegister registers a new client (RFC 7591).
+// The request has to be authorized with one of the configured initial access tokens.
+func (ip *IdentitiyProvider) OauthRegister(w http.ResponseWriter, r *http.Request) {
+	if !ip.checkInitialAccessToken(r) {
+		ip.handleRegistrationError(w, r, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidToken,
+			ErrorDescription: "a valid initial access token is required",
+		})
+		return
+	}
+
+	var req types.ClientRegistrationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRegistrationRequestSize)).Decode(&req); err != nil {
+		ip.handleRegistrationError(w, r, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if req.ClientID != "" || req.ClientSecret != "" {
+		ip.handleRegistrationError(w, r, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+			ErrorDescription: "client_id and client_secret are assigned by the server",
+		})
+		return
+	}
+
+	reg, regErr := ip.clientFromRegistrationRequest(r.Context(), req)
+	if regErr != nil {
+		ip.handleRegistrationError(w, r, regErr)
+		return
+	}
+
+	reg.ClientID = uuid.NewString()
+	reg.ClientIDIssuedAt = time.Now()
+	if requiresClientSecret(reg.TokenEndpointAuthMethod) {
+		reg.ClientSecret = oauth2.GenerateVerifier()
+	}
+
+	registrationAccessToken := oauth2.GenerateVerifier()
+	reg.RegistrationAccessTokenHash = hashRegistrationAccessToken(registrationAccessToken)
+
+	if err := ip.clientStore.SetClient(r.Context(), reg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ip.writeRegistrationResponse(w, r, http.StatusCreated, reg, registrationAccessToken)
+}
+
+// OauthClientConfiguration reads, updates or deletes a registered client (RFC 7592).
+// The request has to be authorized with the registration access token of the client.
+func (ip *IdentitiyProvider) OauthClientConfiguration(w http.ResponseWriter, r *http.Request) {
+	reg, err := ip.clientStore.GetClient(r.Context(), r.PathValue("clientID"))
+	if err != nil || !checkRegistrationAccessToken(r, reg) {
+		// Unknown clients are not revealed, the token is invalid for them as well
+		ip.handleRegistrationError(w, r, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidToken,
+			ErrorDescription: "a valid registration access token is required",
+		})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		ip.writeRegistrationResponse(w, r, http.StatusOK, reg, "")
+	case http.MethodPut:
+		ip.updateClient(w, r, reg)
+	case http.MethodDelete:
+		if err := ip.clientStore.DeleteClient(r.Context(), reg.ClientID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// updateClient replaces the metadata of the client with the ones of the request.
+// The client id, the secret and the registration access token are kept.
+func (ip *IdentitiyProvider) updateClient(w http.ResponseWriter, r *http.Request, reg client.Client) {
+	var req types.ClientRegistrationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRegistrationRequestSize)).Decode(&req); err != nil {
+		ip.handleRegistrationError(w, r, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if req.ClientID != reg.ClientID {
+		ip.handleRegistrationError(w, r, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidRequest,
+			ErrorDescription: "client_id does not match the registered client",
+		})
+		return
+	}
+	if req.ClientSecret != "" && subtle.ConstantTimeCompare([]byte(req.ClientSecret), []byte(reg.ClientSecret)) != 1 {
+		ip.handleRegistrationError(w, r, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidRequest,
+			ErrorDescription: "client_secret does not match the registered client",
+		})
+		return
+	}
+
+	updated, regErr := ip.clientFromRegistrationRequest(r.Context(), req)
+	if regErr != nil {
+		ip.handleRegistrationError(w, r, regErr)
+		return
+	}
+
+	updated.ClientID = reg.ClientID
+	updated.ClientIDIssuedAt = reg.ClientIDIssuedAt
+	updated.RegistrationAccessTokenHash = reg.RegistrationAccessTokenHash
+	// The token exchange policy is set by the operator, so the client can not change it
+	updated.TokenExchangePolicy = reg.TokenExchangePolicy
+	if requiresClientSecret(updated.TokenEndpointAuthMethod) {
+		updated.ClientSecret = reg.ClientSecret
+		if updated.ClientSecret == "" {
+			updated.ClientSecret = oauth2.GenerateVerifier()
+		}
+	}
+
+	if err := ip.clientStore.SetClient(r.Context(), updated); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ip.writeRegistrationResponse(w, r, http.StatusOK, updated, "")
+}
+
+// clientFromRegistrationRequest validates the metadata of the request and fills in the defaults
+func (ip *IdentitiyProvider) clientFromRegistrationRequest(ctx context.Context, req types.ClientRegistrationRequest) (client.Client, *RegistrationError) {
+	reg := client.Client{
+		ClientName:                         req.ClientName,
+		Scope:                              req.Scope,
+		RedirectURIs:                       req.RedirectURIs,
+		PostLogoutRedirectURIs:             req.PostLogoutRedirectURIs,
+		GrantTypes:                         req.GrantTypes,
+		TokenEndpointAuthMethod:            req.TokenEndpointAuthMethod,
+		JWKSURI:                            req.JWKSURI,
+		TLSClientAuthSubjectDN:             req.TLSClientAuthSubjectDN,
+		SubjectType:                        req.SubjectType,
+		SectorIdentifierURI:                req.SectorIdentifierURI,
+		FrontchannelLogoutURI:              req.FrontchannelLogoutURI,
+		BackchannelLogoutURI:               req.BackchannelLogoutURI,
+		BackchannelLogoutSessionRequired:   req.BackchannelLogoutSessionRequired,
+		RequirePushedAuthorizationRequests: req.RequirePushedAuthorizationRequests,
+	}
+
+	for _, redirectURI := range slices.Concat(reg.RedirectURIs, reg.PostLogoutRedirectURIs) {
+		if err := validateRegistrationRedirectURI(redirectURI); err != nil {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidRedirectUri,
+				ErrorDescription: fmt.Sprintf("%s: %s", redirectURI, err),
+			}
+		}
+	}
+
+	if reg.FrontchannelLogoutURI != "" {
+		if err := validateRegistrationRedirectURI(reg.FrontchannelLogoutURI); err != nil {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "frontchannel_logout_uri: " + err.Error(),
+			}
+		}
+	}
+	if reg.BackchannelLogoutURI != "" {
+		if err := validateRegistrationRedirectURI(reg.BackchannelLogoutURI); err != nil {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "backchannel_logout_uri: " + err.Error(),
+			}
+		}
+	}
+
+	if len(reg.GrantTypes) == 0 {
+		reg.GrantTypes = []enums.GrantType{enums.GrantTypeAuthorizationCode, enums.GrantTypeRefreshToken}
+	}
+	for _, grantType := range reg.GrantTypes {
+		if !grantType.IsValid() {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: fmt.Sprintf("unsupported grant_type %s", grantType),
+			}
+		}
+	}
+	for _, responseType := range req.ResponseTypes {
+		if !responseType.IsValid() {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: fmt.Sprintf("unsupported response_type %s", responseType),
+			}
+		}
+		if responseType == enums.ResponseTypeCode && !reg.CheckGrantType(enums.GrantTypeAuthorizationCode) {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "response_type code requires the authorization_code grant_type",
+			}
+		}
+	}
+	if reg.CheckGrantType(enums.GrantTypeAuthorizationCode) && len(reg.RedirectURIs) == 0 {
+		return client.Client{}, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidRedirectUri,
+			ErrorDescription: "the authorization_code grant_type requires at least one redirect_uri",
+		}
+	}
+
+	if len(reg.Scope) == 0 {
+		reg.Scope = slices.Clone(ip.registrationScopes)
+	} else if !ip.supportsScopes(reg.Scope) {
+		return client.Client{}, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+			ErrorDescription: "unsupported scope",
+		}
+	}
+
+	if len(req.JWKS) != 0 {
+		if reg.JWKSURI != "" {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "jwks and jwks_uri must not be used together",
+			}
+		}
+		set, err := jwk.Parse(req.JWKS)
+		if err != nil {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: err.Error(),
+			}
+		}
+		reg.JWKS = set
+	}
+	if reg.JWKSURI != "" {
+		if jwksURI, err := url.Parse(reg.JWKSURI); err != nil || jwksURI.Scheme != "https" {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "jwks_uri has to be a https url",
+			}
+		}
+	}
+
+	if reg.SubjectType == "" {
+		reg.SubjectType = enums.SubjectTypePublic
+	}
+	// Pairwise subjects are only supported with a configured salt
+	if !reg.SubjectType.IsValid() || reg.SubjectType == enums.SubjectTypePairwise && len(ip.pairwiseSubjectSalt) == 0 {
+		return client.Client{}, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+			ErrorDescription: fmt.Sprintf("unsupported subject_type %s", reg.SubjectType),
+		}
+	}
+	if reg.SectorIdentifierURI != "" {
+		if err := ip.validateSectorIdentifierURI(ctx, reg); err != nil {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "sector_identifier_uri: " + err.Error(),
+			}
+		}
+	} else if reg.SubjectType == enums.SubjectTypePairwise && len(redirectURIHosts(reg.RedirectURIs)) > 1 {
+		return client.Client{}, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+			ErrorDescription: "pairwise clients with redirect_uris on multiple hosts require a sector_identifier_uri",
+		}
+	}
+
+	if reg.TokenEndpointAuthMethod == "" {
+		reg.TokenEndpointAuthMethod = enums.EndpointAuthMethodClientSecretBasic
+	}
+	if !slices.Contains(ip.clientAuthMethods, reg.TokenEndpointAuthMethod) {
+		return client.Client{}, &RegistrationError{
+			ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+			ErrorDescription: fmt.Sprintf("token_endpoint_auth_method %s is not supported", reg.TokenEndpointAuthMethod),
+		}
+	}
+
+	switch reg.TokenEndpointAuthMethod {
+	case enums.EndpointAuthMethodPrivateKeyJwt, enums.EndpointAuthMethodSelfSignedTlsClientAuth:
+		if reg.JWKS == nil && reg.JWKSURI == "" {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: fmt.Sprintf("token_endpoint_auth_method %s requires jwks or jwks_uri", reg.TokenEndpointAuthMethod),
+			}
+		}
+	case enums.EndpointAuthMethodTlsClientAuth:
+		if reg.TLSClientAuthSubjectDN == "" {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "token_endpoint_auth_method tls_client_auth requires tls_client_auth_subject_dn",
+			}
+		}
+	case enums.EndpointAuthMethodNone:
+		if reg.CheckGrantType(enums.GrantTypeClientCredentials) {
+			return client.Client{}, &RegistrationError{
+				ErrorType:        RegistrationErrorTypeInvalidClientMetadata,
+				ErrorDescription: "public clients can not use the client_credentials grant_type",
+			}
+		}
+	}
+
+	return reg, nil
+}
+
+// validateSectorIdentifierURI fetches the sector identifier uri of the client,
+// which has to be a json array containing all redirect uris of the client.
+// The uri is fetched with the outbound client, so it can not point to internal services.
+func (ip *IdentitiyProvider) validateSectorIdentifierURI(ctx context.Context, reg client.Client) error {
+	sectorIdentifierURI, err := url.Parse(reg.SectorIdentifierURI)
+	if err != nil || sectorIdentifierURI.Scheme != "https" || sectorIdentifierURI.Host == "" {
+		return errors.New("has to be a https url")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sectorIdentifierTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sectorIdentifierURI.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := ip.outboundHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	var redirectURIs []string
+	if err = json.NewDecoder(io.LimitReader(res.Body, maxSectorIdentifierSize)).Decode(&redirectURIs); err != nil {
+		return err
+	}
+
+	for _, redirectURI := range reg.RedirectURIs {
+		if !slices.Contains(redirectURIs, redirectURI) {
+			return fmt.Errorf("does not contain the redirect uri %s", redirectURI)
+		}
+	}
+	return nil
+}
+
+// redirectURIHosts returns the distinct hosts of the redirect uris
+func redirectURIHosts(redirectURIs []string) []string {
+	var hosts []string
+	for _, redirectURI := range redirectURIs {
+		rURI, err := url.Parse(redirectURI)
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(hosts, rURI.Host) {
+			hosts = append(hosts, rURI.Host)
+		}
+	}
+	return hosts
+}
+
+// validateRegistrationRedirectURI only allows absolute uris without a fragment.
+// Plain http is only allowed for loopback addresses of native apps.
+func validateRegistrationRedirectURI(redirectURI string) error {
+	rURI, err := url.Parse(redirectURI)
+	if err != nil {
+		return err
+	}
+	if !rURI.IsAbs() {
+		return errors.New("redirect uri has to be absolute")
+	}
+	if rURI.Fragment != "" {
+		return errors.New("redirect uri must not contain a fragment")
+	}
+	if rURI.Scheme == "http" {
+		switch rURI.Hostname() {
+		case "localhost", "127.0.0.1", "::1":
+		default:
+			return errors.New("http is only allowed for loopback redirect uris")
+		}
+	}
+	return nil
+}
+
+// requiresClientSecret reports if the server has to issue a secret for the authentication method
+func requiresClientSecret(method enums.EndpointAuthMethod) bool {
+	switch method {
+	case enums.EndpointAuthMethodClientSecretBasic, enums.EndpointAuthMethodClientSecretPost, enums.EndpointAuthMethodClientSecretJwt:
+		return true
+	default:
+		return false
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return token
+}
+
+// checkInitialAccessToken reports if the request contains one of the initial access tokens.
+// Without configured tokens the registration is disabled.
+func (ip *IdentitiyProvider) checkInitialAccessToken(r *http.Request) bool {
+	token := bearerToken(r)
+	if token == "" {
+		return false
+	}
+	for _, initialAccessToken := range ip.initialAccessTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(initialAccessToken)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// The registration access token is only stored as a hash, so it can not be leaked by the client store
+func hashRegistrationAccessToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func checkRegistrationAccessToken(r *http.Request, reg client.Client) bool {
+	token := bearerToken(r)
+	if token == "" || reg.RegistrationAccessTokenHash == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashRegistrationAccessToken(token)), []byte(reg.RegistrationAccessTokenHash)) == 1
+}
+
+func (ip *IdentitiyProvider) writeRegistrationResponse(w http.ResponseWriter, r *http.Request, status int, reg client.Client, registrationAccessToken string) {
+	j := jose.GetJose(r.Context())
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(types.ClientRegistrationResponse{
+		ClientID:                           reg.ClientID,
+		ClientSecret:                       reg.ClientSecret,
+		ClientIDIssuedAt:                   reg.ClientIDIssuedAt.Unix(),
+		ClientSecretExpiresAt:              0,
+		RegistrationAccessToken:            registrationAccessToken,
+		RegistrationClientURI:              j.Issuer() + "/oauth/register/" + url.PathEscape(reg.ClientID),
+		ClientName:                         reg.ClientName,
+		RedirectURIs:                       reg.RedirectURIs,
+		PostLogoutRedirectURIs:             reg.PostLogoutRedirectURIs,
+		GrantTypes:                         reg.GrantTypes,
+		Scope:                              reg.Scope,
+		TokenEndpointAuthMethod:            reg.TokenEndpointAuthMethod,
+		JWKS:                               reg.JWKS,
+		JWKSURI:                            reg.JWKSURI,
+		TLSClientAuthSubjectDN:             reg.TLSClientAuthSubjectDN,
+		SubjectType:                        reg.SubjectType,
+		SectorIdentifierURI:                reg.SectorIdentifierURI,
+		FrontchannelLogoutURI:              reg.FrontchannelLogoutURI,
+		BackchannelLogoutURI:               reg.BackchannelLogoutURI,
+		BackchannelLogoutSessionRequired:   reg.BackchannelLogoutSessionRequired,
+		RequirePushedAuthorizationRequests: reg.RequirePushedAuthorizationRequests,
+	})
+}
+
+func (ip *IdentitiyProvider) handleRegistrationError(w http.ResponseWriter, r *http.Request, err *RegistrationError) {
+	jose := jose.GetJose(r.Context())
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+
+	switch err.ErrorType {
+	case RegistrationErrorTypeInvalidToken:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+jose.Issuer()+`", error="invalid_token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	json.NewEncoder(w).Encode(err)
+}
+
+// ENUM(
+// invalid_request,
+// invalid_token,
+// invalid_redirect_uri,
+// invalid_client_metadata,
+// )
+type RegistrationErrorType string
+
+type RegistrationError struct {
+	ErrorType        RegistrationErrorType `json:"error"`
+	ErrorDescription string                `json:"error_description,omitzero"`
+}
@@ -0,0 +1,67 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package openid
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// RegistrationErrorTypeInvalidRequest is a RegistrationErrorType of type invalid_request.
+	RegistrationErrorTypeInvalidRequest RegistrationErrorType = "invalid_request"
+	// RegistrationErrorTypeInvalidToken is a RegistrationErrorType of type invalid_token.
+	RegistrationErrorTypeInvalidToken RegistrationErrorType = "invalid_token"
+	// RegistrationErrorTypeInvalidRedirectUri is a RegistrationErrorType of type invalid_redirect_uri.
+	RegistrationErrorTypeInvalidRedirectUri RegistrationErrorType = "invalid_redirect_uri"
+	// RegistrationErrorTypeInvalidClientMetadata is a RegistrationErrorType of type invalid_client_metadata.
+	RegistrationErrorTypeInvalidClientMetadata RegistrationErrorType = "invalid_client_metadata"
+)
+
+var ErrInvalidRegistrationErrorType = errors.New("not a valid RegistrationErrorType")
+
+// String implements the Stringer interface.
+func (x RegistrationErrorType) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x RegistrationErrorType) IsValid() bool {
+	_, err := ParseRegistrationErrorType(string(x))
+	return err == nil
+}
+
+var _RegistrationErrorTypeValue = map[string]RegistrationErrorType{
+	"invalid_request":         RegistrationErrorTypeInvalidRequest,
+	"invalid_token":           RegistrationErrorTypeInvalidToken,
+	"invalid_redirect_uri":    RegistrationErrorTypeInvalidRedirectUri,
+	"invalid_client_metadata": RegistrationErrorTypeInvalidClientMetadata,
+}
+
+// ParseRegistrationErrorType attempts to convert a string to a RegistrationErrorType.
+func ParseRegistrationErrorType(name string) (RegistrationErrorType, error) {
+	if x, ok := _RegistrationErrorTypeValue[name]; ok {
+		return x, nil
+	}
+	return RegistrationErrorType(""), fmt.Errorf("%s is %w", name, ErrInvalidRegistrationErrorType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x RegistrationErrorType) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *RegistrationErrorType) UnmarshalText(text []byte) error {
+	tmp, err := ParseRegistrationErrorType(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -0,0 +1,101 @@
+package openid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/url"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+)
+
+// requestObjectReplayPrefix separates the jti of request objects from the ones of client assertions in the replay cache
+const requestObjectReplayPrefix = "request_object:"
+
+// resolveRequestObject verifies the signed request object of the client
+// and returns the form with the parameters of the request object taking precedence over the ones of the query
+func (ip *IdentitiyProvider) resolveRequestObject(ctx context.Context, reg client.Client, form url.Values) (url.Values, error) {
+	set, err := ip.clientKeySet(ctx, reg)
+	if err != nil {
+		return nil, err
+	}
+
+	j := jose.GetJose(ctx)
+	token, err := jwt.ParseString(
+		form.Get(AuthorizeRequestFormValueRequest.String()),
+		jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true), jws.WithRequireKid(false)),
+		jwt.WithIssuer(reg.ClientID),
+		jwt.WithAudience(j.Issuer()),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// A request object with a jti can only be used once
+	if jti, ok := token.JwtID(); ok && jti != "" {
+		exp, _ := token.Expiration()
+		if !ip.replayCache.Add(requestObjectReplayPrefix+reg.ClientID+":"+jti, exp) {
+			return nil, errors.New("request object was already used")
+		}
+	}
+
+	var clientID string
+	if err := token.Get(AuthorizeFormValueClientId.String(), &clientID); err == nil && clientID != reg.ClientID {
+		return nil, errors.New("client_id of the request object does not match")
+	}
+
+	result := maps.Clone(form)
+	result.Del(AuthorizeRequestFormValueRequest.String())
+	for key := range maps.Keys(_AuthorizeFormValueValue) {
+		if !token.Has(key) {
+			continue
+		}
+
+		var value any
+		if err := token.Get(key, &value); err != nil {
+			return nil, err
+		}
+
+		switch v := value.(type) {
+		case string:
+			result.Set(key, v)
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			result.Set(key, string(data))
+		}
+	}
+
+	// resource can be repeated, so it is a string or an array in the request object
+	if token.Has(resourceParam) {
+		var value any
+		if err := token.Get(resourceParam, &value); err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case string:
+			result[resourceParam] = []string{v}
+		case []any:
+			resources := make([]string, 0, len(v))
+			for _, res := range v {
+				str, ok := res.(string)
+				if !ok {
+					return nil, errors.New("resource of the request object has to be a string")
+				}
+				resources = append(resources, str)
+			}
+			result[resourceParam] = resources
+		default:
+			return nil, errors.New("resource of the request object has to be a string or an array")
+		}
+	}
+
+	return result, nil
+}
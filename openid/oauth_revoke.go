@@ -20,14 +20,11 @@ func (ip *IdentitiyProvider) OauthRevoke(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Some oauth clients are sending the client id and secret as basic auth and some as form-values, we allow both
-	clientID := r.FormValue(RevokeFormValueClientId.String())
-	clientSecret := r.FormValue(RevokeFormValueClientSecret.String())
-	if clientID == "" && clientSecret == "" {
-		clientID, clientSecret, _ = r.BasicAuth()
+	_, _, tokenErr := ip.authenticateClient(r)
+	if tokenErr != nil {
+		ip.handleTokenError(w, r, tokenErr)
+		return
 	}
-	r.Form.Set(RevokeFormValueClientId.String(), clientID)
-	r.Form.Set(RevokeFormValueClientSecret.String(), clientSecret)
 
 	params, err := mapFormValueKeys(r.Form, maps.Keys(_RevokeFormValueValue))
 	if err != nil {
@@ -46,20 +43,6 @@ func (ip *IdentitiyProvider) OauthRevoke(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	reg, ok := ip.clients[formValues.ClientID]
-	if !ok {
-		ip.handleTokenError(w, r, &TokenError{
-			ErrorType: TokenErrorTypeInvalidClient,
-		})
-		return
-	}
-	if reg.ClientSecret != "" && formValues.ClientSecret != reg.ClientSecret {
-		ip.handleTokenError(w, r, &TokenError{
-			ErrorType: TokenErrorTypeInvalidClient,
-		})
-		return
-	}
-
 	if formValues.Token == "" {
 		ip.handleTokenError(w, r, &TokenError{
 			ErrorType:        TokenErrorTypeInvalidRequest,
@@ -70,6 +53,14 @@ func (ip *IdentitiyProvider) OauthRevoke(w http.ResponseWriter, r *http.Request)
 
 	j := jose.GetJose(r.Context())
 
+	if _, err := j.ParseAccessToken(formValues.Token); err == nil {
+		ip.handleTokenError(w, r, &TokenError{
+			ErrorType:        TokenErrorType("unsupported_token_type"),
+			ErrorDescription: "access token are not supported",
+		})
+		return
+	}
+
 	token, err := jwt.ParseString(
 		formValues.Token,
 		jwt.WithKeySet(j.PublicKeys()),
@@ -82,13 +73,7 @@ func (ip *IdentitiyProvider) OauthRevoke(w http.ResponseWriter, r *http.Request)
 	var tokenType string
 	token.Get(enums.ClaimTokenType.String(), &tokenType)
 
-	if tokenType == enums.OauthTokenTypeAccessToken.String() {
-		ip.handleTokenError(w, r, &TokenError{
-			ErrorType:        TokenErrorType("unsupported_token_type"),
-			ErrorDescription: "access token are not supported",
-		})
-		return
-	} else if tokenType == enums.OauthTokenTypeRefreshToken.String() {
+	if tokenType == enums.OauthTokenTypeRefreshToken.String() {
 		var sessionID string
 		token.Get(enums.ClaimSid.String(), &sessionID)
 
@@ -3,7 +3,7 @@ package openid
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"maps"
 	"net/http"
 	"slices"
@@ -11,16 +11,17 @@ import (
 
 	"github.com/akatranlp/go-pkg/its"
 	"github.com/akatranlp/sentinel/account"
+	"github.com/akatranlp/sentinel/client"
 	"github.com/akatranlp/sentinel/jose"
 	"github.com/akatranlp/sentinel/openid/enums"
 	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/token"
 	"github.com/akatranlp/sentinel/utils"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/google/uuid"
 
 	"github.com/lestrrat-go/jwx/v3/jwt"
 	"golang.org/x/oauth2"
-	"golang.org/x/sync/singleflight"
 )
 
 func (ip *IdentitiyProvider) OauthToken(w http.ResponseWriter, r *http.Request) {
@@ -32,14 +33,11 @@ func (ip *IdentitiyProvider) OauthToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Some oauth clients are sending the client id and secret as basic auth and some as form-values, we allow both
-	clientID := r.FormValue(TokenFormValueClientId.String())
-	clientSecret := r.FormValue(TokenFormValueClientSecret.String())
-	if clientID == "" && clientSecret == "" {
-		clientID, clientSecret, _ = r.BasicAuth()
+	reg, authMethod, tokenErr := ip.authenticateClient(r)
+	if tokenErr != nil {
+		ip.handleTokenError(w, r, tokenErr)
+		return
 	}
-	r.Form.Set(TokenFormValueClientId.String(), clientID)
-	r.Form.Set(TokenFormValueClientSecret.String(), clientSecret)
 
 	var formValues types.TokenRequest
 	config := utils.CreateDecoderConfig()
@@ -69,32 +67,43 @@ func (ip *IdentitiyProvider) OauthToken(w http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
-
-	reg, ok := ip.clients[formValues.ClientID]
-	if !ok {
-		ip.handleTokenError(w, r, &TokenError{
-			ErrorType: TokenErrorTypeInvalidClient,
-		})
-		return
-	}
-	if reg.ClientSecret != "" && formValues.ClientSecret != reg.ClientSecret {
+	if !reg.CheckGrantType(formValues.GrantType) {
 		ip.handleTokenError(w, r, &TokenError{
-			ErrorType: TokenErrorTypeInvalidClient,
+			ErrorType:        TokenErrorTypeUnauthorizedClient,
+			ErrorDescription: "the client is not allowed to use this grant type",
 		})
 		return
 	}
 
-	switch formValues.GrantType {
-	case enums.GrantTypeAuthorizationCode:
-		authReq, ok := ip.validateCode(formValues.Code)
-		if !ok {
+	formValues.Resource = r.Form[resourceParam]
+	formValues.Audience = r.Form[audienceParam]
+	formValues.Confirmation = tokenConfirmation(r)
+
+	if r.Header.Get(dpopHeader) != "" {
+		ip.setDPoPNonce(w)
+		jkt, err := ip.validateDPoPProof(r, "")
+		if errors.Is(err, ErrUseDPoPNonce) {
 			ip.handleTokenError(w, r, &TokenError{
-				ErrorType:        TokenErrorTypeInvalidGrant,
-				ErrorDescription: "invalid or expired code",
+				ErrorType:        TokenErrorTypeUseDpopNonce,
+				ErrorDescription: err.Error(),
 			})
 			return
+		} else if err != nil {
+			ip.handleTokenError(w, r, &TokenError{
+				ErrorType:        TokenErrorTypeInvalidDpopProof,
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+		if formValues.Confirmation == nil {
+			formValues.Confirmation = &types.Confirmation{}
 		}
-		res, err := ip.handleAuthorizationCode(r.Context(), authReq, formValues)
+		formValues.Confirmation.JKT = jkt
+	}
+
+	switch formValues.GrantType {
+	case enums.GrantTypeAuthorizationCode:
+		res, err := ip.handleAuthorizationCode(r.Context(), formValues)
 		if err != nil {
 			ip.handleTokenError(w, r, err)
 			return
@@ -119,23 +128,43 @@ func (ip *IdentitiyProvider) OauthToken(w http.ResponseWriter, r *http.Request)
 		json.NewEncoder(w).Encode(res)
 		return
 
+	case enums.GrantTypeDeviceCode:
+		res, err := ip.handleDeviceCode(r.Context(), formValues)
+		if err != nil {
+			ip.handleTokenError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+		return
+
+	case enums.GrantTypeClientCredentials:
+		res, err := ip.handleClientCredentials(r.Context(), reg, authMethod, formValues)
+		if err != nil {
+			ip.handleTokenError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+		return
+
 	case enums.GrantTypeTokenExchange:
-		if reg.TokenExchangeSecret != "" && formValues.TokenExchangeSecret == reg.TokenExchangeSecret || reg.TokenExchangeSecret == "" && reg.ClientSecret != "" {
-			res, err := ip.handleTokenExchange(r.Context(), formValues)
-			if err != nil {
-				ip.handleTokenError(w, r, err)
-				return
-			}
-
-			w.Header().Set("Cache-Control", "no-store")
-			w.Header().Set("Pragma", "no-cache")
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(res)
+		res, err := ip.handleTokenExchange(r.Context(), reg, formValues)
+		if err != nil {
+			ip.handleTokenError(w, r, err)
 			return
 		}
-		ip.handleTokenError(w, r, &TokenError{
-			ErrorType: TokenErrorTypeInvalidClient,
-		})
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
 		return
 
 	default:
@@ -148,16 +177,35 @@ func (ip *IdentitiyProvider) OauthToken(w http.ResponseWriter, r *http.Request)
 
 }
 
-func (ip *IdentitiyProvider) handleAuthorizationCode(ctx context.Context, authReq AuthTokenValues, tokenReq types.TokenRequest) (types.TokenResponse, *TokenError) {
+func (ip *IdentitiyProvider) handleAuthorizationCode(ctx context.Context, tokenReq types.TokenRequest) (types.TokenResponse, *TokenError) {
+	// The code is only redeemed, when the request matches the authorization, so a wrong request does not burn it
+	authReq, tokenErr := ip.redeemCode(ctx, tokenReq)
+	if tokenErr != nil {
+		return types.TokenResponse{}, tokenErr
+	}
+
+	tokenRes, tokenErr := ip.createUserTokens(ctx, authReq, tokenReq)
+	if tokenErr != nil {
+		return types.TokenResponse{}, tokenErr
+	}
+	if tokenErr := ip.checkCodeReplay(ctx, tokenReq.Code, authReq.SessionID); tokenErr != nil {
+		return types.TokenResponse{}, tokenErr
+	}
+	return tokenRes, nil
+}
+
+// checkCodeBinding checks that the token request comes from the client and redirect uri of the authorization
+// and has the verifier of its code challenge
+func checkCodeBinding(authReq types.AuthRequest, tokenReq types.TokenRequest) *TokenError {
 	rURIStr := authReq.RedirectURI.String()
 	if authReq.ClientID != tokenReq.ClientID {
-		return types.TokenResponse{}, &TokenError{
+		return &TokenError{
 			ErrorType:        TokenErrorTypeInvalidGrant,
 			ErrorDescription: "clientID does not match with initial auth request",
 		}
 	}
 	if rURIStr != tokenReq.RedirectURI {
-		return types.TokenResponse{}, &TokenError{
+		return &TokenError{
 			ErrorType:        TokenErrorTypeInvalidGrant,
 			ErrorDescription: "redirect uri does not match with initial auth request",
 		}
@@ -165,14 +213,14 @@ func (ip *IdentitiyProvider) handleAuthorizationCode(ctx context.Context, authRe
 
 	// Mitigate PKCE downgrade attack
 	if tokenReq.CodeVerifier != "" && authReq.CodeChallengeMethod == "" {
-		return types.TokenResponse{}, &TokenError{
+		return &TokenError{
 			ErrorType:        TokenErrorTypeInvalidGrant,
 			ErrorDescription: "invalid code challenge verifier",
 		}
 	}
 
 	if authReq.CodeChallengeMethod == enums.CodeChallengeMethodPlain && tokenReq.CodeVerifier != authReq.CodeChallenge {
-		return types.TokenResponse{}, &TokenError{
+		return &TokenError{
 			ErrorType:        TokenErrorTypeInvalidGrant,
 			ErrorDescription: "invalid code challenge verifier",
 		}
@@ -180,13 +228,20 @@ func (ip *IdentitiyProvider) handleAuthorizationCode(ctx context.Context, authRe
 
 	if authReq.CodeChallengeMethod == enums.CodeChallengeMethodS256 &&
 		oauth2.S256ChallengeFromVerifier(tokenReq.CodeVerifier) != authReq.CodeChallenge {
-		return types.TokenResponse{}, &TokenError{
+		return &TokenError{
 			ErrorType:        TokenErrorTypeInvalidGrant,
 			ErrorDescription: "invalid code challenge verifier",
 		}
 	}
 
-	sessionID := uuid.NewString()
+	return nil
+}
+
+func (ip *IdentitiyProvider) createUserTokens(ctx context.Context, authReq AuthTokenValues, tokenReq types.TokenRequest) (types.TokenResponse, *TokenError) {
+	sessionID := authReq.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
 	user, err := ip.userStore.GetUserByID(ctx, account.UserID(authReq.UserID))
 	if err != nil {
 		return types.TokenResponse{}, &TokenError{
@@ -195,28 +250,62 @@ func (ip *IdentitiyProvider) handleAuthorizationCode(ctx context.Context, authRe
 		}
 	}
 
+	reg, err := ip.clientStore.GetClient(ctx, authReq.ClientID)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: err.Error(),
+		}
+	}
 	openID := slices.Contains(authReq.Scope, enums.ScopeOpenid)
 	offlineAccess := slices.Contains(authReq.Scope, enums.ScopeOfflineAccess)
 
+	resources, err := ip.tokenResources(ctx, authReq.ClientID, tokenReq.Resource, authReq.Resource)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		}
+	}
+	resourceAud, resourceScope, err := resourceAudience(resources, authReq.Scope)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		}
+	}
+
 	j := jose.GetJose(ctx)
 
+	claims, err := ip.userClaims(ctx, j.Issuer(), user, ip.releasedClaims(authReq.Scope, authReq.Claims.IDToken), authReq.Claims.IDToken)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
+	}
+
 	currTime := time.Now()
 
 	tokens, err := j.CreateTokens(jose.IDTokenCreateArg{
 		TokenCreateArg: jose.TokenCreateArg{
-			CurrTime:  currTime,
-			Subject:   authReq.UserID,
-			Audience:  []string{authReq.ClientID},
-			SessionID: sessionID,
-			Scope:     authReq.Scope,
+			CurrTime:      currTime,
+			Subject:       ip.subjectIdentifier(ctx, reg, authReq.UserID),
+			Audience:      []string{authReq.ClientID},
+			SessionID:     sessionID,
+			Scope:         authReq.Scope,
+			ClientID:      authReq.ClientID,
+			Resources:     resourceAud,
+			ResourceScope: resourceScope,
+			AuthTime:      authReq.AuthTime,
+			ACR:           authReq.ACR,
+			AMR:           authReq.AMR,
+			Confirmation:  tokenReq.Confirmation,
+			// Public clients have no credentials, so the refresh token is bound to their key instead
+			BindRefreshToken: reg.IsPublic(),
 		},
-		Nonce:         authReq.Nonce,
-		AuthTime:      authReq.AuthTime,
-		Email:         user.Email,
-		EmailVerified: user.EmailVerified,
-		Name:          user.Name,
-		Username:      user.Username,
-		Picture:       user.Picture,
+		Nonce:  authReq.Nonce,
+		Claims: claims,
 	}, offlineAccess, openID)
 
 	if err != nil {
@@ -228,17 +317,47 @@ func (ip *IdentitiyProvider) handleAuthorizationCode(ctx context.Context, authRe
 
 	accessExpiresIn := int(utils.Bang(tokens.AccessToken.Expiration()).Sub(currTime) / time.Second)
 
+	claimsRequest, err := authReq.Claims.MarshalText()
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	// The session is stored even without a refresh token, so the client can be notified on logout
+	sess := token.Session{
+		SessionID: sessionID,
+		UserID:    authReq.UserID,
+		ClientID:  authReq.ClientID,
+		Expiry:    utils.Bang(tokens.AccessToken.Expiration()),
+		Claims:    string(claimsRequest),
+		AuthTime:  authReq.AuthTime,
+		ACR:       authReq.ACR,
+		AMR:       authReq.AMR,
+		Resources: authReq.Resource,
+	}
+
 	var refreshExpiresIn int
 	if tokens.RefreshToken != nil {
 		refreshExpiresIn = int(utils.Bang(tokens.RefreshToken.Expiration()).Sub(currTime) / time.Second)
 
-		ip.tokenStore.SetSession(ctx, sessionID, utils.Bang(tokens.RefreshToken.JwtID()), time.Now().Add(time.Duration(refreshExpiresIn)*time.Second))
+		sess.RefreshJTI = utils.Bang(tokens.RefreshToken.JwtID())
+		sess.Expiry = utils.Bang(tokens.RefreshToken.Expiration())
+	}
+
+	if err = ip.tokenStore.SetSession(ctx, sess); err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
 	}
 
 	return types.TokenResponse{
 		AccessToken:      tokens.SignedAccessToken,
 		ExpiresIn:        accessExpiresIn,
-		TokenType:        "Bearer",
+		TokenType:        tokenType(tokenReq.Confirmation),
+		Scope:            resourceScope,
 		IDToken:          tokens.SignedIDToken,
 		RefreshToken:     tokens.SignedRefreshToken,
 		RefreshExpiresIn: refreshExpiresIn,
@@ -253,7 +372,9 @@ func (ip *IdentitiyProvider) handleTokenError(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "application/json")
 
 	switch err.ErrorType {
-	case TokenErrorTypeInvalidRequest, TokenErrorTypeUnsupportedGrantType, TokenErrorTypeInvalidScope:
+	case TokenErrorTypeInvalidRequest, TokenErrorTypeUnsupportedGrantType, TokenErrorTypeInvalidScope,
+		TokenErrorTypeAuthorizationPending, TokenErrorTypeSlowDown, TokenErrorTypeAccessDenied, TokenErrorTypeExpiredToken, TokenErrorTypeInvalidRequestObject,
+		TokenErrorTypeInvalidDpopProof, TokenErrorTypeUseDpopNonce, TokenErrorTypeInvalidTarget:
 		w.WriteHeader(http.StatusBadRequest)
 	case TokenErrorTypeInvalidClient:
 		w.WriteHeader(http.StatusUnauthorized)
@@ -284,6 +405,13 @@ func (ip *IdentitiyProvider) handleRefreshToken(ctx context.Context, req types.T
 		}
 	}
 
+	if err := checkTokenBinding(parsedRefreshToken, req.Confirmation); err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorDescription: err.Error(),
+		}
+	}
+
 	var scopeStr string
 	parsedRefreshToken.Get(enums.ClaimScope.String(), &scopeStr)
 
@@ -296,16 +424,14 @@ func (ip *IdentitiyProvider) handleRefreshToken(ctx context.Context, req types.T
 		}
 	}
 
-	var sessionID string
-	parsedRefreshToken.Get(enums.ClaimSid.String(), &sessionID)
-
-	sess, err := ip.tokenStore.GetSession(ctx, sessionID)
+	sess, err := ip.tokenSession(ctx, parsedRefreshToken)
 	if err != nil {
 		return types.TokenResponse{}, &TokenError{
 			ErrorType:        TokenErrorTypeInvalidGrant,
 			ErrorDescription: err.Error(),
 		}
 	}
+	sessionID := sess.SessionID
 	if sess.RefreshJTI != utils.Bang(parsedRefreshToken.JwtID()) {
 		ip.tokenStore.RevokeSession(ctx, sessionID)
 		return types.TokenResponse{}, &TokenError{
@@ -314,20 +440,46 @@ func (ip *IdentitiyProvider) handleRefreshToken(ctx context.Context, req types.T
 		}
 	}
 
-	userID := utils.Bang(parsedRefreshToken.Subject())
-
-	// TODO: Go oder nicht go das ist hier die Frage
+	userID := sess.UserID
 
-	// go ip.refreshAccountToken(account.UserID(userID))
-	ip.refreshAccountToken(account.UserID(userID))
+	reg, err := ip.clientStore.GetClient(ctx, req.ClientID)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidClient,
+			ErrorDescription: err.Error(),
+		}
+	}
+	resources, err := ip.tokenResources(ctx, req.ClientID, req.Resource, sess.Resources)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		}
+	}
+	resourceAud, resourceScope, err := resourceAudience(resources, scopes)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		}
+	}
 
 	currTime := time.Now()
 	arg := jose.TokenCreateArg{
-		CurrTime:  currTime,
-		Subject:   userID,
-		Audience:  utils.Bang(parsedRefreshToken.Audience()),
-		SessionID: sessionID,
-		Scope:     scopes,
+		CurrTime:      currTime,
+		Subject:       ip.subjectIdentifier(ctx, reg, userID),
+		Audience:      utils.Bang(parsedRefreshToken.Audience()),
+		SessionID:     sessionID,
+		Scope:         scopes,
+		ClientID:      req.ClientID,
+		Resources:     resourceAud,
+		ResourceScope: resourceScope,
+		AuthTime:      sess.AuthTime,
+		ACR:           sess.ACR,
+		AMR:           sess.AMR,
+		Confirmation:  req.Confirmation,
+		// Public clients have no credentials, so the refresh token is bound to their key instead
+		BindRefreshToken: reg.IsPublic(),
 	}
 
 	accessToken, signedAccessToken, err := j.CreateAccessToken(arg)
@@ -348,9 +500,10 @@ func (ip *IdentitiyProvider) handleRefreshToken(ctx context.Context, req types.T
 
 	accessExpiresIn := int(utils.Bang(accessToken.Expiration()).Sub(currTime) / time.Second)
 	refreshExpiresIn := int(utils.Bang(refreshToken.Expiration()).Sub(currTime) / time.Second)
-	tokenID := utils.Bang(refreshToken.JwtID())
 
-	if err := ip.tokenStore.SetSession(ctx, sessionID, tokenID, time.Now().Add(time.Duration(refreshExpiresIn)*time.Second)); err != nil {
+	sess.RefreshJTI = utils.Bang(refreshToken.JwtID())
+	sess.Expiry = utils.Bang(refreshToken.Expiration())
+	if err := ip.tokenStore.SetSession(ctx, sess); err != nil {
 		return types.TokenResponse{}, &TokenError{
 			ErrorType:        TokenErrorTypeServerError,
 			ErrorDescription: err.Error(),
@@ -360,48 +513,53 @@ func (ip *IdentitiyProvider) handleRefreshToken(ctx context.Context, req types.T
 	return types.TokenResponse{
 		AccessToken:      signedAccessToken,
 		ExpiresIn:        accessExpiresIn,
-		TokenType:        "Bearer",
+		TokenType:        tokenType(req.Confirmation),
+		Scope:            resourceScope,
 		RefreshToken:     signedRefreshToken,
 		RefreshExpiresIn: refreshExpiresIn,
 	}, nil
 }
 
-func (ip *IdentitiyProvider) handleTokenExchange(ctx context.Context, req types.TokenRequest) (types.TokenResponse, *TokenError) {
-	if req.RequestedTokenType != enums.OauthTokenTypeAccessToken || req.SubjectTokenType != enums.OauthTokenTypeAccessToken {
+// clientSubjectPrefix separates the subjects of client credentials tokens from the ids of users
+const clientSubjectPrefix = "client:"
+
+func clientSubject(clientID string) string {
+	return clientSubjectPrefix + clientID
+}
+
+func (ip *IdentitiyProvider) handleClientCredentials(ctx context.Context, reg client.Client, authMethod enums.EndpointAuthMethod, req types.TokenRequest) (types.TokenResponse, *TokenError) {
+	// Only clients which proved their credentials in this request can use the grant
+	if authMethod == enums.EndpointAuthMethodNone {
 		return types.TokenResponse{}, &TokenError{
-			ErrorType:        TokenErrorTypeInvalidGrant,
-			ErrorDescription: enums.ErrInvalidOauthTokenType.Error(),
+			ErrorType:        TokenErrorTypeUnauthorizedClient,
+			ErrorDescription: "public clients are not allowed to use the client credentials grant",
 		}
 	}
-	j := jose.GetJose(ctx)
 
-	parsedRefreshToken, err := jwt.ParseString(
-		req.SubjectToken,
-		jwt.WithKeySet(j.PublicKeys()),
-		jwt.WithIssuer(j.Issuer()),
-		jwt.WithAudience(req.ClientID),
-		jwt.WithClaimValue(enums.ClaimTokenType.String(), enums.OauthTokenTypeAccessToken.String()),
-	)
-	if err != nil {
+	// There is no user involved, so the user specific scopes are never granted
+	scopes := req.Scope
+	if len(scopes) == 0 {
+		scopes = slices.DeleteFunc(slices.Clone(reg.Scope), func(scope enums.Scope) bool {
+			return scope == enums.ScopeOpenid || scope == enums.ScopeOfflineAccess
+		})
+	}
+
+	if !its.All(slices.Values(scopes), func(scope enums.Scope) bool {
+		return scope != enums.ScopeOpenid && scope != enums.ScopeOfflineAccess && slices.Contains(reg.Scope, scope)
+	}) {
 		return types.TokenResponse{}, &TokenError{
-			ErrorType:        TokenErrorTypeInvalidGrant,
-			ErrorDescription: err.Error(),
+			ErrorType: TokenErrorTypeInvalidScope,
 		}
 	}
 
-	var sessionID string
-	parsedRefreshToken.Get(enums.ClaimSid.String(), &sessionID)
-
-	if _, err = ip.tokenStore.GetSession(ctx, sessionID); err != nil {
+	resources, err := ip.lookupResources(ctx, reg.ClientID, req.Resource)
+	if err != nil {
 		return types.TokenResponse{}, &TokenError{
-			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorType:        TokenErrorTypeInvalidTarget,
 			ErrorDescription: err.Error(),
 		}
 	}
-
-	userID := utils.Bang(parsedRefreshToken.Subject())
-
-	acc, err := ip.userStore.GetAccountByProvider(ctx, account.UserID(userID), req.RequestedIssuer)
+	resourceAud, resourceScope, err := resourceAudience(resources, scopes)
 	if err != nil {
 		return types.TokenResponse{}, &TokenError{
 			ErrorType:        TokenErrorTypeInvalidTarget,
@@ -409,73 +567,32 @@ func (ip *IdentitiyProvider) handleTokenExchange(ctx context.Context, req types.
 		}
 	}
 
-	return types.TokenResponse{
-		AccessToken:     acc.AccessToken,
-		ExpiresIn:       int(acc.Expiry.Sub(time.Now()) / time.Second),
-		TokenType:       acc.TokenType,
-		IssuedTokenType: req.RequestedTokenType,
-	}, nil
-}
-
-var g singleflight.Group
+	j := jose.GetJose(ctx)
 
-func (ip *IdentitiyProvider) refreshAccountToken(userID account.UserID) {
-	_, err, _ := g.Do(string(userID), func() (any, error) {
-		ctx := context.Background()
-		accounts, err := ip.userStore.GetAccountsForUserID(ctx, userID)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, acc := range accounts {
-			p, ok := ip.providers[acc.Provider]
-			if !ok {
-				fmt.Println("TODO: could this happen?")
-				return nil, err
-			}
-			token, err := p.RefreshToken(ctx, &oauth2.Token{
-				AccessToken:  acc.AccessToken,
-				RefreshToken: acc.RefreshToken,
-				Expiry:       acc.Expiry,
-				TokenType:    acc.TokenType,
-			})
-			if err != nil {
-				fmt.Println("Refresh Error", acc.Provider, err)
-				return nil, err
-			}
-
-			var refreshExpiry time.Time
-			if refreshExpiresIn, ok := token.Extra("refresh_expires_in").(int64); ok {
-				refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
-			}
-			if refreshExpiresIn, ok := token.Extra("refresh_token_expires_in").(int64); ok {
-				refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
-			}
-
-			var idToken string
-			if idTokenStr, ok := token.Extra("id_token").(string); ok {
-				idToken = idTokenStr
-			}
-
-			acc.AccessToken = token.AccessToken
-			acc.Expiry = token.Expiry
-			acc.RefreshToken = token.RefreshToken
-			acc.RefreshExpiry = refreshExpiry
-			acc.TokenType = token.TokenType
-			if idToken != "" {
-				acc.IDToken = idToken
-			}
-
-			if err := ip.userStore.UpdateAccount(ctx, acc.AccountID, acc); err != nil {
-				fmt.Println(err)
-				return nil, err
-			}
-		}
-		return nil, nil
+	currTime := time.Now()
+	accessToken, signedAccessToken, err := j.CreateAccessToken(jose.TokenCreateArg{
+		CurrTime:      currTime,
+		Subject:       clientSubject(reg.ClientID),
+		Audience:      []string{reg.ClientID},
+		Scope:         scopes,
+		ClientID:      reg.ClientID,
+		Resources:     resourceAud,
+		ResourceScope: resourceScope,
+		Confirmation:  req.Confirmation,
 	})
 	if err != nil {
-		return
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
 	}
+
+	return types.TokenResponse{
+		AccessToken: signedAccessToken,
+		ExpiresIn:   int(utils.Bang(accessToken.Expiration()).Sub(currTime) / time.Second),
+		TokenType:   tokenType(req.Confirmation),
+		Scope:       resourceScope,
+	}, nil
 }
 
 // ENUM(
@@ -491,7 +608,9 @@ func (ip *IdentitiyProvider) refreshAccountToken(userID account.UserID) {
 // requested_issuer,
 // subject_token,
 // subject_token_type,
-// __token_exchange_secret__,
+// actor_token,
+// actor_token_type,
+// device_code,
 // )
 type TokenFormValue string
 
@@ -504,6 +623,13 @@ type TokenFormValue string
 // invalid_scope,
 // server_error,
 // invalid_target,
+// authorization_pending,
+// slow_down,
+// access_denied,
+// expired_token,
+// invalid_request_object,
+// invalid_dpop_proof,
+// use_dpop_nonce,
 // )
 type TokenErrorType string
 
@@ -28,6 +28,20 @@ const (
 	TokenErrorTypeServerError TokenErrorType = "server_error"
 	// TokenErrorTypeInvalidTarget is a TokenErrorType of type invalid_target.
 	TokenErrorTypeInvalidTarget TokenErrorType = "invalid_target"
+	// TokenErrorTypeAuthorizationPending is a TokenErrorType of type authorization_pending.
+	TokenErrorTypeAuthorizationPending TokenErrorType = "authorization_pending"
+	// TokenErrorTypeSlowDown is a TokenErrorType of type slow_down.
+	TokenErrorTypeSlowDown TokenErrorType = "slow_down"
+	// TokenErrorTypeAccessDenied is a TokenErrorType of type access_denied.
+	TokenErrorTypeAccessDenied TokenErrorType = "access_denied"
+	// TokenErrorTypeExpiredToken is a TokenErrorType of type expired_token.
+	TokenErrorTypeExpiredToken TokenErrorType = "expired_token"
+	// TokenErrorTypeInvalidRequestObject is a TokenErrorType of type invalid_request_object.
+	TokenErrorTypeInvalidRequestObject TokenErrorType = "invalid_request_object"
+	// TokenErrorTypeInvalidDpopProof is a TokenErrorType of type invalid_dpop_proof.
+	TokenErrorTypeInvalidDpopProof TokenErrorType = "invalid_dpop_proof"
+	// TokenErrorTypeUseDpopNonce is a TokenErrorType of type use_dpop_nonce.
+	TokenErrorTypeUseDpopNonce TokenErrorType = "use_dpop_nonce"
 )
 
 var ErrInvalidTokenErrorType = errors.New("not a valid TokenErrorType")
@@ -53,6 +67,13 @@ var _TokenErrorTypeValue = map[string]TokenErrorType{
 	"invalid_scope":          TokenErrorTypeInvalidScope,
 	"server_error":           TokenErrorTypeServerError,
 	"invalid_target":         TokenErrorTypeInvalidTarget,
+	"authorization_pending":  TokenErrorTypeAuthorizationPending,
+	"slow_down":              TokenErrorTypeSlowDown,
+	"access_denied":          TokenErrorTypeAccessDenied,
+	"expired_token":          TokenErrorTypeExpiredToken,
+	"invalid_request_object": TokenErrorTypeInvalidRequestObject,
+	"invalid_dpop_proof":     TokenErrorTypeInvalidDpopProof,
+	"use_dpop_nonce":         TokenErrorTypeUseDpopNonce,
 }
 
 // ParseTokenErrorType attempts to convert a string to a TokenErrorType.
@@ -103,8 +124,12 @@ const (
 	TokenFormValueSubjectToken TokenFormValue = "subject_token"
 	// TokenFormValueSubjectTokenType is a TokenFormValue of type subject_token_type.
 	TokenFormValueSubjectTokenType TokenFormValue = "subject_token_type"
-	// TokenFormValueTokenExchangeSecret is a TokenFormValue of type __token_exchange_secret__.
-	TokenFormValueTokenExchangeSecret TokenFormValue = "__token_exchange_secret__"
+	// TokenFormValueActorToken is a TokenFormValue of type actor_token.
+	TokenFormValueActorToken TokenFormValue = "actor_token"
+	// TokenFormValueActorTokenType is a TokenFormValue of type actor_token_type.
+	TokenFormValueActorTokenType TokenFormValue = "actor_token_type"
+	// TokenFormValueDeviceCode is a TokenFormValue of type device_code.
+	TokenFormValueDeviceCode TokenFormValue = "device_code"
 )
 
 var ErrInvalidTokenFormValue = errors.New("not a valid TokenFormValue")
@@ -122,19 +147,21 @@ func (x TokenFormValue) IsValid() bool {
 }
 
 var _TokenFormValueValue = map[string]TokenFormValue{
-	"client_id":                 TokenFormValueClientId,
-	"client_secret":             TokenFormValueClientSecret,
-	"redirect_uri":              TokenFormValueRedirectUri,
-	"grant_type":                TokenFormValueGrantType,
-	"code":                      TokenFormValueCode,
-	"code_verifier":             TokenFormValueCodeVerifier,
-	"scope":                     TokenFormValueScope,
-	"refresh_token":             TokenFormValueRefreshToken,
-	"requested_token_type":      TokenFormValueRequestedTokenType,
-	"requested_issuer":          TokenFormValueRequestedIssuer,
-	"subject_token":             TokenFormValueSubjectToken,
-	"subject_token_type":        TokenFormValueSubjectTokenType,
-	"__token_exchange_secret__": TokenFormValueTokenExchangeSecret,
+	"client_id":            TokenFormValueClientId,
+	"client_secret":        TokenFormValueClientSecret,
+	"redirect_uri":         TokenFormValueRedirectUri,
+	"grant_type":           TokenFormValueGrantType,
+	"code":                 TokenFormValueCode,
+	"code_verifier":        TokenFormValueCodeVerifier,
+	"scope":                TokenFormValueScope,
+	"refresh_token":        TokenFormValueRefreshToken,
+	"requested_token_type": TokenFormValueRequestedTokenType,
+	"requested_issuer":     TokenFormValueRequestedIssuer,
+	"subject_token":        TokenFormValueSubjectToken,
+	"subject_token_type":   TokenFormValueSubjectTokenType,
+	"actor_token":          TokenFormValueActorToken,
+	"actor_token_type":     TokenFormValueActorTokenType,
+	"device_code":          TokenFormValueDeviceCode,
 }
 
 // ParseTokenFormValue attempts to convert a string to a TokenFormValue.
@@ -0,0 +1,52 @@
+package openid
+
+import (
+	"testing"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+)
+
+func TestClientCredentialsRequiresAuthentication(t *testing.T) {
+	ip := newTestProvider(t, WithClients(client.Client{ClientID: "public", Scope: enums.Scopes{enums.ScopeProfile}}))
+	ctx := testContext(t, ip)
+	reg, err := ip.clientStore.GetClient(ctx, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, tokenErr := ip.handleClientCredentials(ctx, reg, enums.EndpointAuthMethodNone, types.TokenRequest{
+		ClientID:  "public",
+		GrantType: enums.GrantTypeClientCredentials,
+	})
+	if tokenErr == nil || tokenErr.ErrorType != TokenErrorTypeUnauthorizedClient {
+		t.Fatalf("expected unauthorized_client, got %v", tokenErr)
+	}
+}
+
+func TestClientCredentialsSubject(t *testing.T) {
+	ip := newTestProvider(t, WithClients(client.Client{ClientID: "machine", ClientSecret: "s3cret", Scope: enums.Scopes{enums.ScopeProfile}}))
+	ctx := testContext(t, ip)
+	reg, err := ip.clientStore.GetClient(ctx, "machine")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, tokenErr := ip.handleClientCredentials(ctx, reg, enums.EndpointAuthMethodClientSecretBasic, types.TokenRequest{
+		ClientID:  "machine",
+		GrantType: enums.GrantTypeClientCredentials,
+	})
+	if tokenErr != nil {
+		t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+	}
+
+	token, err := jose.GetJose(ctx).ParseAccessToken(res.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub, _ := token.Subject(); sub != clientSubject("machine") {
+		t.Errorf("expected the subject %s, got %s", clientSubject("machine"), sub)
+	}
+}
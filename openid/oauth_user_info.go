@@ -2,48 +2,115 @@ package openid
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/akatranlp/sentinel/account"
 	"github.com/akatranlp/sentinel/jose"
 	"github.com/akatranlp/sentinel/openid/enums"
 	"github.com/akatranlp/sentinel/openid/types"
 	"github.com/akatranlp/sentinel/utils"
-	"github.com/lestrrat-go/jwx/v3/jwt"
 )
 
 func (ip *IdentitiyProvider) OauthUserInfo(w http.ResponseWriter, r *http.Request) {
 	j := jose.GetJose(r.Context())
-	token, err := jwt.ParseHeader(
-		r.Header, "Authorization",
-		jwt.WithIssuer(j.Issuer()),
-		jwt.WithKeySet(j.PublicKeys()),
-		jwt.WithClaimValue(enums.ClaimTokenType.String(), enums.OauthTokenTypeAccessToken.String()),
-	)
+
+	scheme, accessToken, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	isDPoP := strings.EqualFold(scheme, dpopHeader)
+	if !isDPoP && !strings.EqualFold(scheme, "Bearer") || accessToken == "" {
+		ip.handleUserInfoError(w, r, "invalid_request", "no access token provided")
+		return
+	}
+
+	token, err := j.ParseAccessToken(accessToken)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Header().Set("WWW-Authenticate", `Bearer realm="`+j.Issuer()+`, error="invalid_token", error_description="`+err.Error()+`"`)
+		ip.handleUserInfoError(w, r, "invalid_token", err.Error())
+		return
+	}
+
+	cnf := tokenConfirmation(r)
+	if isDPoP {
+		ip.setDPoPNonce(w)
+		jkt, err := ip.validateDPoPProof(r, accessToken)
+		if errors.Is(err, ErrUseDPoPNonce) {
+			ip.handleUserInfoError(w, r, "use_dpop_nonce", err.Error())
+			return
+		} else if err != nil {
+			ip.handleUserInfoError(w, r, "invalid_dpop_proof", err.Error())
+			return
+		}
+		if cnf == nil {
+			cnf = &types.Confirmation{}
+		}
+		cnf.JKT = jkt
+	}
+
+	// A DPoP bound token can not be downgraded to a bearer token, so its proof is always required
+	if err := checkTokenBinding(token, cnf); err != nil {
+		ip.handleUserInfoError(w, r, "invalid_token", err.Error())
 		return
 	}
 
-	userID := utils.Bang(token.Subject())
-	user, err := ip.userStore.GetUserByID(r.Context(), account.UserID(userID))
+	sess, err := ip.tokenSession(r.Context(), token)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Header().Set("WWW-Authenticate", `Bearer realm="`+j.Issuer()+`, error="invalid_token", error_description="`+err.Error()+`"`)
+		ip.handleUserInfoError(w, r, "invalid_token", err.Error())
+		return
+	}
+
+	user, err := ip.userStore.GetUserByID(r.Context(), account.UserID(sess.UserID))
+	if err != nil {
+		ip.handleUserInfoError(w, r, "invalid_token", err.Error())
+		return
+	}
+
+	var claimsRequest types.ClaimsRequest
+	if sess.Claims != "" {
+		if err := claimsRequest.UnmarshalText([]byte(sess.Claims)); err != nil {
+			ip.handleUserInfoError(w, r, "invalid_token", err.Error())
+			return
+		}
+	}
+
+	var scopeStr string
+	token.Get(enums.ClaimScope.String(), &scopeStr)
+
+	var scopes enums.Scopes
+	scopes.UnmarshalText([]byte(scopeStr))
+
+	claims, err := ip.userClaims(r.Context(), j.Issuer(), user, ip.releasedClaims(scopes, claimsRequest.UserInfo), claimsRequest.UserInfo)
+	if err != nil {
+		ip.handleUserInfoError(w, r, "server_error", err.Error())
 		return
 	}
 
+	res := types.UserInfoResponse(claims)
+	// The subject has to match the one of the id token, which can be pairwise
+	res[enums.ClaimSub.String()] = utils.Bang(token.Subject())
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(types.UserInfoResponse{
-		Subject:           userID,
-		Email:             user.Email,
-		EmailVerified:     user.EmailVerified,
-		Name:              user.Name,
-		PreferredUsername: user.Username,
-		Nickname:          user.Username,
-		Picture:           user.Picture,
-		Profile:           j.Issuer() + "/user",
-	})
+	json.NewEncoder(w).Encode(res)
+}
+
+func (ip *IdentitiyProvider) handleUserInfoError(w http.ResponseWriter, r *http.Request, errorType, errorDescription string) {
+	j := jose.GetJose(r.Context())
+	params := fmt.Sprintf(`realm="%s", error="%s", error_description=%q`, j.Issuer(), errorType, errorDescription)
+
+	var algs []string
+	for _, alg := range enums.SigningAlgValueValues() {
+		if !alg.IsSymmetric() {
+			algs = append(algs, alg.String())
+		}
+	}
+
+	// The client has to know which schemes are supported, so both challenges are sent
+	w.Header().Add("WWW-Authenticate", "Bearer "+params)
+	w.Header().Add("WWW-Authenticate", dpopHeader+" "+params+`, algs="`+strings.Join(algs, " ")+`"`)
 
+	if errorType == "invalid_request" {
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		w.WriteHeader(http.StatusUnauthorized)
+	}
 }
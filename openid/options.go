@@ -1,23 +1,59 @@
 package openid
 
 import (
+	"crypto/x509"
+	"errors"
 	"io"
 	"io/fs"
+	"maps"
+	"net/http"
 	"time"
 
+	"github.com/akatranlp/sentinel/authcode"
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/consent"
+	"github.com/akatranlp/sentinel/device"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/par"
 	"github.com/akatranlp/sentinel/provider"
+	"github.com/akatranlp/sentinel/resource"
 	"github.com/lestrrat-go/jwx/v3/jwk"
 )
 
 type OptionFn func(*ipConfig) error
 
-// WithClients is the Option to create a clientRegistration.
+// WithClients is the Option to seed the client store with clients.
 // Use this multiple times to register more clients.
-func WithClients(clients ...ClientRegistration) OptionFn {
+func WithClients(clients ...client.Client) OptionFn {
 	return func(ic *ipConfig) error {
-		for _, client := range clients {
-			ic.clients[client.ClientID] = client
-		}
+		ic.clients = append(ic.clients, clients...)
+		return nil
+	}
+}
+
+// WithClientAuthMethods is the Option to restrict the methods clients can authenticate with.
+// By default all methods are enabled, the mutual-TLS methods only with WithTLS.
+func WithClientAuthMethods(methods ...enums.EndpointAuthMethod) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.clientAuthMethods = methods
+		return nil
+	}
+}
+
+// WithInitialAccessTokens is the Option to enable the dynamic client registration.
+// Clients can only be registered with one of these tokens as bearer token.
+func WithInitialAccessTokens(tokens ...string) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.initialAccessTokens = append(ic.initialAccessTokens, tokens...)
+		return nil
+	}
+}
+
+// WithRegistrationScopes is the Option to set the scopes of registered clients, which do not request scopes.
+// By default they only get the openid scope.
+func WithRegistrationScopes(scopes ...enums.Scope) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.registrationScopes = scopes
 		return nil
 	}
 }
@@ -33,6 +69,209 @@ func WithProviders(ps ...provider.Provider) OptionFn {
 	}
 }
 
+// WithScopeClaims is the Option to set the claims which are released for a scope.
+// Custom scopes are accepted in requests as soon as they release claims.
+// Use WithClaimValues to provide the values of custom claims.
+func WithScopeClaims(scope enums.Scope, claims ...string) OptionFn {
+	return func(ic *ipConfig) error {
+		// The default policy is shared, so it is copied before it is changed
+		ic.scopeClaims = maps.Clone(ic.scopeClaims)
+		ic.scopeClaims[scope] = claims
+		return nil
+	}
+}
+
+// WithClaimValues is the Option to provide the values of custom claims for the users.
+func WithClaimValues(fn ClaimValuesFunc) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.claimValues = fn
+		return nil
+	}
+}
+
+// WithGrantStore is the Option to persist the scopes users have consented to.
+// If it is not set an in-memory store is used.
+func WithGrantStore(store consent.GrantStore) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.grantStore = store
+		return nil
+	}
+}
+
+// WithResources is the Option to seed the resource store with the protected resources.
+// Use this multiple times to register more resources.
+func WithResources(resources ...resource.Resource) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.resources = append(ic.resources, resources...)
+		return nil
+	}
+}
+
+// WithResourceStore is the Option to persist the protected resources clients can request access tokens for.
+// If it is not set an in-memory store is used.
+func WithResourceStore(store resource.ResourceStore) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.resourceStore = store
+		return nil
+	}
+}
+
+// WithAuthCodeStore is the Option to persist the authorization codes until they are redeemed.
+// If it is not set an in-memory store is used, which only works with a single instance.
+func WithAuthCodeStore(store authcode.AuthCodeStore) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.authCodeStore = store
+		return nil
+	}
+}
+
+// WithAuthCodeLifeTime is the Option to set how long an authorization code can be redeemed
+func WithAuthCodeLifeTime(lifeTime time.Duration) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.authCodeLifeTime = lifeTime
+		return nil
+	}
+}
+
+// WithAuthCodeRetention is the Option to set how long redeemed authorization codes are kept,
+// so a replay of the code revokes the issued tokens
+func WithAuthCodeRetention(retention time.Duration) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.authCodeRetention = retention
+		return nil
+	}
+}
+
+// WithSecurityEvents is the Option to receive security events, like replayed authorization codes.
+// If it is not set the events are logged.
+func WithSecurityEvents(fn SecurityEventFunc) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.securityEvents = fn
+		return nil
+	}
+}
+
+// WithPushedRequestStore is the Option to persist the pushed authorization requests until they are used.
+// If it is not set an in-memory store is used, which only works with a single instance.
+func WithPushedRequestStore(store par.PushedRequestStore) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.pushedRequestStore = store
+		return nil
+	}
+}
+
+// WithDeviceCodeStore is the Option to persist the pending device authorization requests.
+// If it is not set an in-memory store is used.
+func WithDeviceCodeStore(store device.DeviceCodeStore) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.deviceCodeStore = store
+		return nil
+	}
+}
+
+// WithErrorHandler is the Option to receive the errors of the background work, like failed refreshes of upstream tokens.
+// If it is not set the errors are logged.
+func WithErrorHandler(fn ErrorFunc) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.errorHandler = fn
+		return nil
+	}
+}
+
+// WithAccountRefreshInterval is the Option to set how often the account refresh looks for expiring upstream tokens
+func WithAccountRefreshInterval(interval time.Duration) OptionFn {
+	return func(ic *ipConfig) error {
+		if interval <= 0 {
+			return errors.New("the account refresh interval has to be positive")
+		}
+		ic.accountRefreshInterval = interval
+		return nil
+	}
+}
+
+// WithAccountRefreshWindow is the Option to set how long before their expiry the upstream tokens are refreshed
+func WithAccountRefreshWindow(window time.Duration) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.accountRefreshWindow = window
+		return nil
+	}
+}
+
+// WithAccountRefreshConcurrency is the Option to limit how many upstream tokens are refreshed at the same time
+func WithAccountRefreshConcurrency(concurrency int) OptionFn {
+	return func(ic *ipConfig) error {
+		if concurrency <= 0 {
+			return errors.New("the account refresh concurrency has to be positive")
+		}
+		ic.accountRefreshConcurrency = concurrency
+		return nil
+	}
+}
+
+func WithDeviceCodeLifeTime(lifeTime time.Duration) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.deviceCodeLifeTime = lifeTime
+		return nil
+	}
+}
+
+// WithBackchannelLogoutTimeout is the Option to set how long a client can take to answer a logout token
+func WithBackchannelLogoutTimeout(timeout time.Duration) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.backchannelLogoutTimeout = timeout
+		return nil
+	}
+}
+
+// WithBackchannelLogoutRetries is the Option to set how often a logout token is resent,
+// if the client is not reachable or responds with a server error
+func WithBackchannelLogoutRetries(retries int) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.backchannelLogoutRetries = retries
+		return nil
+	}
+}
+
+// WithDPoPNonceLifeTime is the Option to require server nonces in DPoP proofs.
+// The nonce is rotated after the lifetime, if it is not set no nonces are required.
+func WithDPoPNonceLifeTime(lifeTime time.Duration) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.dpopNonceLifeTime = lifeTime
+		return nil
+	}
+}
+
+// WithOutboundHTTPClient is the Option to set the client, which fetches the urls registered by clients, like jwks_uri.
+// If it is not set a client is used, which only connects to public addresses and does not follow redirects.
+func WithOutboundHTTPClient(httpClient *http.Client) OptionFn {
+	return func(ic *ipConfig) error {
+		if httpClient == nil {
+			return errors.New("outbound http client must not be nil")
+		}
+		ic.outboundHTTPClient = httpClient
+		return nil
+	}
+}
+
+// WithTLS is the Option to serve the identity provider with TLS.
+// Client certificates are requested, so clients can use mutual-TLS authentication.
+func WithTLS(certFile, keyFile string) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.tlsCertFile = certFile
+		ic.tlsKeyFile = keyFile
+		return nil
+	}
+}
+
+// WithTLSClientCAs is the Option to set the CAs client certificates of tls_client_auth are verified with.
+// If it is not set the system roots are used.
+func WithTLSClientCAs(pool *x509.CertPool) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.tlsClientCAs = pool
+		return nil
+	}
+}
+
 func WithAccessTokenExpiration(expiration time.Duration) OptionFn {
 	return func(ic *ipConfig) error {
 		ic.atExpiration = expiration
@@ -47,6 +286,15 @@ func WithRefreshTokenExpiration(expiration time.Duration) OptionFn {
 	}
 }
 
+// WithAccessTokenFormat is the Option to set the format of the access tokens.
+// The legacy format is the default, so existing resource servers keep working, RFC 9068 has to be enabled.
+func WithAccessTokenFormat(format enums.AccessTokenFormat) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.accessTokenFormat = format
+		return nil
+	}
+}
+
 func WithPublicURL(publicURL string) OptionFn {
 	return func(ic *ipConfig) error {
 		ic.publicURL = publicURL
@@ -96,6 +344,15 @@ func WithSigningKeyReader(r io.Reader) OptionFn {
 	}
 }
 
+// WithPairwiseSubjectSalt is the Option to set the secret salt of the pairwise subject identifiers.
+// It is required for pairwise clients and has to stay the same, otherwise the identifiers of the users change.
+func WithPairwiseSubjectSalt(salt []byte) OptionFn {
+	return func(ic *ipConfig) error {
+		ic.pairwiseSubjectSalt = salt
+		return nil
+	}
+}
+
 func WithAppURL(appURL string) OptionFn {
 	return func(ic *ipConfig) error {
 		ic.appURL = appURL
@@ -0,0 +1,180 @@
+package openid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/consent"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/openid/web"
+	csrf "github.com/akatranlp/sentinel/session/gorilla_csrf"
+)
+
+func (ip *IdentitiyProvider) ConsentPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !ip.sessionManager.IsAuthed(ctx) {
+		http.Redirect(w, r, ip.basePath+"/login", http.StatusTemporaryRedirect)
+		return
+	}
+
+	authReq, ok := ip.sessionManager.PeekAuthRequest(ctx)
+	if !ok {
+		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "no pending authorization request", http.StatusBadRequest)
+		return
+	}
+
+	reg, err := ip.clientStore.GetClient(ctx, authReq.ClientID)
+	if err != nil {
+		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "invalid client id", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	web.Consent(ip.sessionManager.CsrfFormField(), csrf.Token(r), clientDisplayName(reg), authReq.Scope).Render(ctx, w)
+}
+
+func (ip *IdentitiyProvider) Consent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !ip.sessionManager.IsAuthed(ctx) {
+		http.Redirect(w, r, ip.basePath+"/login", http.StatusFound)
+		return
+	}
+
+	authReq, ok := ip.sessionManager.GetAuthRequest(ctx)
+	if !ok {
+		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "no pending authorization request", http.StatusBadRequest)
+		return
+	}
+
+	userID := ip.sessionManager.GetAuth(ctx)
+
+	switch r.FormValue("action") {
+	case "allow":
+		// The new scopes are added to the ones which were granted before
+		grant, err := ip.grantStore.GetGrant(ctx, userID, authReq.ClientID)
+		if err != nil && !errors.Is(err, consent.ErrGrantNotFound) {
+			handleClientError(w, r, authReq, AuthorizeError{AuthorizeErrorTypeServerError, err.Error()})
+			return
+		}
+		for _, scope := range authReq.Scope {
+			if !slices.Contains(grant.Scope, scope) {
+				grant.Scope = append(grant.Scope, scope)
+			}
+		}
+		grant.UserID = userID
+		grant.ClientID = authReq.ClientID
+		grant.GrantedAt = time.Now()
+
+		if err = ip.grantStore.SetGrant(ctx, grant); err != nil {
+			handleClientError(w, r, authReq, AuthorizeError{AuthorizeErrorTypeServerError, err.Error()})
+			return
+		}
+
+		ip.sendAuthResponse(w, r, AuthTokenValues{
+			AuthRequest: authReq,
+			UserID:      userID,
+			AuthTime:    ip.sessionManager.GetAuthTime(ctx),
+			ACR:         ip.sessionManager.GetACR(ctx),
+			AMR:         ip.sessionManager.GetAMR(ctx),
+		})
+	case "deny":
+		handleClientError(w, r, authReq, AuthorizeError{
+			AuthorizeErrorTypeAccessDenied,
+			"the user denied the request",
+		})
+	default:
+		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "invalid action", http.StatusBadRequest)
+	}
+}
+
+// continueAuthorization sends the authorization response for the logged in user
+// or asks the user for the consent first, if the requested scopes were not granted yet
+func (ip *IdentitiyProvider) continueAuthorization(w http.ResponseWriter, r *http.Request, authReq types.AuthRequest, flashMessages ...templ.Component) {
+	ctx := r.Context()
+	userID := ip.sessionManager.GetAuth(ctx)
+
+	reg, err := ip.clientStore.GetClient(ctx, authReq.ClientID)
+	if err != nil {
+		sendErrorPage(w, r, AuthorizeErrorTypeInvalidRequest.String(), "invalid client id", http.StatusBadRequest)
+		return
+	}
+
+	// A requested sub means the client only accepts this user (OpenID Connect Core 5.5.1)
+	for _, requests := range []map[string]types.ClaimRequest{authReq.Claims.IDToken, authReq.Claims.UserInfo} {
+		if !requests[enums.ClaimSub.String()].Matches(ip.subjectIdentifier(ctx, reg, userID)) {
+			handleClientError(w, r, authReq, AuthorizeError{
+				AuthorizeErrorTypeLoginRequired,
+				"the requested subject is not logged in",
+			})
+			return
+		}
+	}
+
+	// Voluntary acr values are best effort, only an essential acr is enforced
+	if acrs, essential := requestedACRs(authReq); essential && !acrSatisfied(acrs, ip.sessionManager.GetACR(ctx)) {
+		handleClientError(w, r, authReq, AuthorizeError{
+			AuthorizeErrorTypeUnmetAuthenticationRequirements,
+			"the login does not satisfy the requested acr",
+		})
+		return
+	}
+
+	required, err := ip.consentRequired(ctx, reg, userID, authReq)
+	if err != nil {
+		handleClientError(w, r, authReq, AuthorizeError{AuthorizeErrorTypeServerError, err.Error()})
+		return
+	}
+
+	if required {
+		if authReq.Prompt == enums.PromptNone {
+			handleClientError(w, r, authReq, AuthorizeError{
+				ErrorType: AuthorizeErrorTypeConsentRequired,
+			})
+			return
+		}
+		ip.sessionManager.SetAuthRequest(ctx, authReq)
+		http.Redirect(w, r, ip.basePath+"/consent", http.StatusFound)
+		return
+	}
+
+	ip.sendAuthResponse(w, r, AuthTokenValues{
+		AuthRequest: authReq,
+		UserID:      userID,
+		AuthTime:    ip.sessionManager.GetAuthTime(ctx),
+		ACR:         ip.sessionManager.GetACR(ctx),
+		AMR:         ip.sessionManager.GetAMR(ctx),
+	}, flashMessages...)
+}
+
+// consentRequired reports if the user has to be asked before the client gets access to the requested scopes.
+// prompt=consent always asks the user, even for first-party clients.
+func (ip *IdentitiyProvider) consentRequired(ctx context.Context, reg client.Client, userID string, authReq types.AuthRequest) (bool, error) {
+	if authReq.Prompt == enums.PromptConsent {
+		return true, nil
+	}
+	if reg.FirstParty {
+		return false, nil
+	}
+
+	grant, err := ip.grantStore.GetGrant(ctx, userID, reg.ClientID)
+	if errors.Is(err, consent.ErrGrantNotFound) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return !grant.Covers(authReq.Scope), nil
+}
+
+func clientDisplayName(reg client.Client) string {
+	if reg.ClientName != "" {
+		return reg.ClientName
+	}
+	return reg.ClientID
+}
@@ -0,0 +1,73 @@
+package openid
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/akatranlp/sentinel/device"
+	"github.com/akatranlp/sentinel/openid/web"
+	csrf "github.com/akatranlp/sentinel/session/gorilla_csrf"
+)
+
+func (ip *IdentitiyProvider) DevicePage(w http.ResponseWriter, r *http.Request) {
+	userCode := normalizeUserCode(r.URL.Query().Get("user_code"))
+
+	if !ip.sessionManager.IsAuthed(r.Context()) {
+		redirect := ip.basePath + "/device"
+		if userCode != "" {
+			redirect += "?" + url.Values{"user_code": {userCode}}.Encode()
+		}
+		http.Redirect(w, r, ip.basePath+"/login?"+url.Values{"redirect": {redirect}}.Encode(), http.StatusTemporaryRedirect)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	web.DeviceCode(ip.sessionManager.CsrfFormField(), csrf.Token(r), userCode, "").Render(r.Context(), w)
+}
+
+func (ip *IdentitiyProvider) DeviceVerify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !ip.sessionManager.IsAuthed(ctx) {
+		http.Redirect(w, r, ip.basePath+"/login", http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	userCode := normalizeUserCode(r.FormValue("user_code"))
+	code, err := ip.deviceCodeStore.GetDeviceCodeByUserCode(ctx, userCode)
+	if err != nil || code.Status != device.StatusPending || code.Expiry.Before(time.Now()) {
+		w.WriteHeader(http.StatusBadRequest)
+		web.DeviceCode(ip.sessionManager.CsrfFormField(), csrf.Token(r), userCode, "The code is invalid or has expired").Render(ctx, w)
+		return
+	}
+
+	var userID string
+	var authTime time.Time
+	var status device.Status
+	switch r.FormValue("action") {
+	case "approve":
+		status = device.StatusApproved
+		userID = ip.sessionManager.GetAuth(ctx)
+		authTime = ip.sessionManager.GetAuthTime(ctx)
+	case "deny":
+		status = device.StatusDenied
+	default:
+		web.DeviceConfirm(ip.sessionManager.CsrfFormField(), csrf.Token(r), code.UserCode, code.ClientID, code.Scope).Render(ctx, w)
+		return
+	}
+
+	err = ip.deviceCodeStore.UpdateDeviceCodeStatus(ctx, code.DeviceCode, status, userID, authTime)
+	if errors.Is(err, device.ErrDeviceCodeNotPending) || errors.Is(err, device.ErrDeviceCodeNotFound) {
+		w.WriteHeader(http.StatusBadRequest)
+		web.DeviceCode(ip.sessionManager.CsrfFormField(), csrf.Token(r), userCode, "The code is invalid or has expired").Render(ctx, w)
+		return
+	} else if err != nil {
+		sendErrorPage(w, r, TokenErrorTypeServerError.String(), err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	web.DeviceResult(status == device.StatusApproved).Render(ctx, w)
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"maps"
 	"net/http"
+	"net/url"
 	"os"
 	"slices"
 	"strings"
@@ -23,7 +24,11 @@ func (ip *IdentitiyProvider) LoginPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	provs := slices.SortedFunc(its.Map21(maps.All(ip.providers), func(slug string, p provider.Provider) web.Provider {
+	redirect := ip.loginRedirect(r)
+
+	providers := ip.loginProviders(r.Context())
+
+	provs := slices.SortedFunc(its.Map21(maps.All(providers), func(slug string, p provider.Provider) web.Provider {
 		return web.Provider{
 			Name:    p.GetName(),
 			Slug:    slug,
@@ -34,9 +39,9 @@ func (ip *IdentitiyProvider) LoginPage(w http.ResponseWriter, r *http.Request) {
 		return strings.Compare(a.Slug, b.Slug)
 	})
 
-	reactProvs := slices.SortedFunc(its.Map21(maps.All(ip.providers), func(slug string, p provider.Provider) types.Provider {
+	reactProvs := slices.SortedFunc(its.Map21(maps.All(providers), func(slug string, p provider.Provider) types.Provider {
 		return types.Provider{
-			LoginURL:    fmt.Sprintf("%s/%s/login?redirect=%s", ip.basePath, slug, "/auth/"),
+			LoginURL:    fmt.Sprintf("%s/%s/login?redirect=%s", ip.basePath, slug, url.QueryEscape(redirect)),
 			Alias:       string(p.GetType()),
 			ProviderID:  slug,
 			DisplayName: p.GetName(),
@@ -57,5 +62,15 @@ func (ip *IdentitiyProvider) LoginPage(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(os.Stderr, err)
 	}
 
-	web.Login(provs, ip.sessionManager.CsrfFormField(), csrf.Token(r), "/auth/").Render(r.Context(), io.Discard)
+	web.Login(provs, ip.sessionManager.CsrfFormField(), csrf.Token(r), redirect).Render(r.Context(), io.Discard)
+}
+
+// loginRedirect returns the page the user wants to get back to after the login.
+// Only pages of the identity provider itself are allowed, otherwise we would have an open redirect
+func (ip *IdentitiyProvider) loginRedirect(r *http.Request) string {
+	redirect := r.URL.Query().Get("redirect")
+	if !strings.HasPrefix(redirect, ip.basePath+"/") || strings.HasPrefix(redirect, "//") {
+		return ip.basePath + "/"
+	}
+	return redirect
 }
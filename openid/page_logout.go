@@ -45,7 +45,7 @@ func (ip *IdentitiyProvider) LogoutPage(w http.ResponseWriter, r *http.Request)
 		fmt.Fprintln(os.Stderr, err)
 	}
 
-	web.Logout(ip.sessionManager.CsrfFormField(), csrf.Token(r), "", "").Render(r.Context(), io.Discard)
+	web.Logout(ip.sessionManager.CsrfFormField(), csrf.Token(r), "").Render(r.Context(), io.Discard)
 }
 
 func (ip *IdentitiyProvider) Logout(w http.ResponseWriter, r *http.Request) {
@@ -54,11 +54,5 @@ func (ip *IdentitiyProvider) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := ip.sessionManager.Destroy(r.Context()); err != nil {
-		// TODO: Render errorPage
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	http.Redirect(w, r, ip.basePath+"/login", http.StatusFound)
+	ip.finishLogout(w, r, "", ip.basePath+"/login")
 }
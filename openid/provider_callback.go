@@ -1,6 +1,7 @@
 package openid
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -91,6 +92,8 @@ func (ip *IdentitiyProvider) ProviderCallback(w http.ResponseWriter, r *http.Req
 	userInfo.RefreshExpiry = refreshExpiry
 	userInfo.IDToken = idToken
 	userInfo.TokenType = oauthToken.TokenType
+	// The login brings new tokens, so an account which had to be linked again works again
+	userInfo.RelinkRequired = false
 
 	var user account.User
 	var flashMessages []templ.Component
@@ -114,43 +117,7 @@ func (ip *IdentitiyProvider) ProviderCallback(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		for _, acc := range accounts {
-			if acc.Provider == provider {
-				continue
-			}
-			provider := ip.providers[acc.Provider]
-			token, err := provider.RefreshToken(r.Context(), &oauth2.Token{AccessToken: acc.AccessToken, RefreshToken: acc.RefreshToken})
-			if err != nil {
-				// TODO: Add Message to manually relink account
-				flashMessages = append(flashMessages, components.GitHubIcon(""))
-				continue
-			}
-
-			var refreshExpiry time.Time
-			if refreshExpiresIn, ok := token.Extra("refresh_expires_in").(int64); ok {
-				refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
-			}
-			if refreshExpiresIn, ok := token.Extra("refresh_token_expires_in").(int64); ok {
-				refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
-			}
-
-			var idToken string
-			if idTokenStr, ok := token.Extra("id_token").(string); ok {
-				idToken = idTokenStr
-			}
-
-			acc.AccessToken = token.AccessToken
-			acc.Expiry = token.Expiry
-			acc.RefreshToken = token.RefreshToken
-			acc.RefreshExpiry = refreshExpiry
-			acc.IDToken = idToken
-			acc.TokenType = token.TokenType
-			if err := ip.userStore.UpdateAccount(r.Context(), acc.AccountID, acc); err != nil {
-				// TODO: Add Message to manually relink account
-				flashMessages = append(flashMessages, components.GitHubIcon(""))
-				continue
-			}
-		}
+		flashMessages = ip.refreshLinkedAccounts(ctx, accounts, provider)
 	} else {
 		// check if user already exists
 		// save tokens into database
@@ -183,54 +150,16 @@ func (ip *IdentitiyProvider) ProviderCallback(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		for _, acc := range accounts {
-			if acc.Provider == provider {
-				continue
-			}
-			provider := ip.providers[acc.Provider]
-			token, err := provider.RefreshToken(r.Context(), &oauth2.Token{AccessToken: acc.AccessToken, RefreshToken: acc.RefreshToken})
-			if err != nil {
-				// TODO: Add Message to manually relink account
-				flashMessages = append(flashMessages, components.GitHubIcon(""))
-				continue
-			}
-
-			var refreshExpiry time.Time
-			if refreshExpiresIn, ok := token.Extra("refresh_expires_in").(int64); ok {
-				refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
-			}
-			if refreshExpiresIn, ok := token.Extra("refresh_token_expires_in").(int64); ok {
-				refreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
-			}
-
-			var idToken string
-			if idTokenStr, ok := token.Extra("id_token").(string); ok {
-				idToken = idTokenStr
-			}
-
-			acc.AccessToken = token.AccessToken
-			acc.Expiry = token.Expiry
-			acc.RefreshToken = token.RefreshToken
-			acc.RefreshExpiry = refreshExpiry
-			acc.IDToken = idToken
-			acc.TokenType = token.TokenType
-			if err := ip.userStore.UpdateAccount(r.Context(), acc.AccountID, acc); err != nil {
-				// TODO: Add Message to manually relink account
-				flashMessages = append(flashMessages, components.GitHubIcon(""))
-				continue
-			}
-		}
+		flashMessages = ip.refreshLinkedAccounts(ctx, accounts, provider)
 
 		ip.sessionManager.SetAuth(ctx, string(user.UserID))
+		authContext := p.GetAuthContext()
+		ip.sessionManager.SetAuthContext(ctx, authContext.ACR, authContext.AMR)
 	}
 
 	authReq, ok := ip.sessionManager.GetAuthRequest(ctx)
 	if ok {
-		ip.sendAuthResponse(w, r, AuthTokenValues{
-			AuthRequest: authReq,
-			UserID:      string(user.UserID),
-			AuthTime:    ip.sessionManager.GetAuthTime(ctx),
-		}, flashMessages...)
+		ip.continueAuthorization(w, r, authReq, flashMessages...)
 		return
 	}
 
@@ -249,3 +178,19 @@ func (ip *IdentitiyProvider) ProviderCallback(w http.ResponseWriter, r *http.Req
 
 	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
 }
+
+// refreshLinkedAccounts refreshes the expiring tokens of the other providers of the user after a login.
+// The refreshes share the coalescing of the other refreshes, because the providers rotate the refresh tokens.
+func (ip *IdentitiyProvider) refreshLinkedAccounts(ctx context.Context, accounts []account.Account, provider string) []templ.Component {
+	var flashMessages []templ.Component
+	for _, acc := range accounts {
+		if acc.Provider == provider || !accountExpiring(acc) {
+			continue
+		}
+		if _, err := ip.refreshAccount(ctx, acc); err != nil {
+			// TODO: Add Message to manually relink account
+			flashMessages = append(flashMessages, components.GitHubIcon(""))
+		}
+	}
+	return flashMessages
+}
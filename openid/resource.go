@@ -0,0 +1,82 @@
+package openid
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/resource"
+)
+
+// resourceParam can be repeated, so it is not part of the decoded form values
+const resourceParam = "resource"
+
+var (
+	ErrInvalidResource      = errors.New("the resource has to be an absolute uri without fragment")
+	ErrUnauthorizedResource = errors.New("the client is not allowed to access the resource")
+	ErrResourceNotGranted   = errors.New("the resource was not granted in the authorization request")
+	ErrNoResourceScope      = errors.New("the resources accept none of the granted scopes")
+)
+
+// lookupResources validates the resource indicators the client requested (RFC 8707 section 2)
+func (ip *IdentitiyProvider) lookupResources(ctx context.Context, clientID string, uris []string) ([]resource.Resource, error) {
+	resources := make([]resource.Resource, 0, len(uris))
+	for _, uri := range uris {
+		resURL, err := url.Parse(uri)
+		if err != nil || !resURL.IsAbs() || resURL.Fragment != "" {
+			return nil, ErrInvalidResource
+		}
+
+		res, err := ip.resourceStore.GetResource(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		if !res.AllowsClient(clientID) {
+			return nil, ErrUnauthorizedResource
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
+
+// tokenResources returns the resources of an access token.
+// If resources were granted before, the requested ones have to be among them.
+// Without a request the access token is issued for all granted resources.
+func (ip *IdentitiyProvider) tokenResources(ctx context.Context, clientID string, requested, granted []string) ([]resource.Resource, error) {
+	if len(requested) == 0 {
+		requested = granted
+	} else if len(granted) > 0 {
+		for _, uri := range requested {
+			if !slices.Contains(granted, uri) {
+				return nil, ErrResourceNotGranted
+			}
+		}
+	}
+	return ip.lookupResources(ctx, clientID, requested)
+}
+
+// resourceAudience returns the audience of an access token for the resources
+// and restricts the scope to the scopes the resources accept
+func resourceAudience(resources []resource.Resource, scope enums.Scopes) ([]string, enums.Scopes, error) {
+	if len(resources) == 0 {
+		return nil, nil, nil
+	}
+
+	audience := make([]string, 0, len(resources))
+	for _, res := range resources {
+		audience = append(audience, res.URI)
+	}
+
+	resourceScope := slices.DeleteFunc(slices.Clone(scope), func(s enums.Scope) bool {
+		return !slices.ContainsFunc(resources, func(res resource.Resource) bool {
+			return slices.Contains(res.Scope, s)
+		})
+	})
+	if len(resourceScope) == 0 {
+		return nil, nil, ErrNoResourceScope
+	}
+
+	return audience, resourceScope, nil
+}
@@ -2,12 +2,11 @@ package openid
 
 import (
 	"fmt"
-	"maps"
 	"net/http"
 	"net/url"
 
-	"github.com/akatranlp/go-pkg/its"
 	"github.com/akatranlp/go-pkg/middleware"
+	"github.com/akatranlp/sentinel/client"
 	"github.com/akatranlp/sentinel/jose"
 	"github.com/akatranlp/sentinel/openid/web/assets"
 	"github.com/akatranlp/sentinel/openid/web/shared"
@@ -34,14 +33,15 @@ func (ip *IdentitiyProvider) Handler() http.Handler {
 			if origin == "https://jwt.io" {
 				return true
 			}
-			return its.Any(maps.Values(ip.clients), func(client ClientRegistration) bool {
-				return client.CheckRedirectURI(*originURL) == nil
-			})
+			ok, err := ip.clientStore.IsAllowedOrigin(r.Context(), client.Origin(*originURL))
+			return err == nil && ok
 		},
 		AllowedMethods: []string{
 			http.MethodHead,
 			http.MethodGet,
 			http.MethodPost,
+			http.MethodPut,
+			http.MethodDelete,
 		},
 		AllowedHeaders:   []string{"*"},
 		AllowCredentials: false,
@@ -88,6 +88,7 @@ func (ip *IdentitiyProvider) Handler() http.Handler {
 			})
 		})
 		r.Use(ip.sessionManager.LoadAndSave)
+		r.Use(ip.sessionManager.BrowserStateMiddleware)
 
 		r.Group(func(r chi.Router) {
 			r.Use(ip.sessionManager.CsrfMiddleware())
@@ -101,6 +102,12 @@ func (ip *IdentitiyProvider) Handler() http.Handler {
 			r.Get("/user", ip.UserPage)
 			r.Get("/user/edit", ip.UserEditPage)
 			r.Post("/user/edit", ip.UserEdit)
+
+			r.Get("/consent", ip.ConsentPage)
+			r.Post("/consent", ip.Consent)
+
+			r.Get("/device", ip.DevicePage)
+			r.Post("/device", ip.DeviceVerify)
 		})
 
 		r.Route("/oauth", func(r chi.Router) {
@@ -108,13 +115,20 @@ func (ip *IdentitiyProvider) Handler() http.Handler {
 
 			r.Get("/authorize", ip.OauthAuthorize)
 			r.Post("/authorize", ip.OauthAuthorize)
+			r.Post("/par", ip.OauthPushedAuthorization)
 
 			r.Post("/token", ip.OauthToken)
+			r.Post("/device_authorization", ip.OauthDeviceAuthorization)
 
 			r.Post("/introspect", ip.OauthIntrospect)
 			r.Post("/revoke", ip.OauthRevoke)
 			r.Get("/userinfo", ip.OauthUserInfo)
 			r.Post("/userinfo", ip.OauthUserInfo)
+
+			r.Post("/register", ip.OauthRegister)
+			r.Get("/register/{clientID}", ip.OauthClientConfiguration)
+			r.Put("/register/{clientID}", ip.OauthClientConfiguration)
+			r.Delete("/register/{clientID}", ip.OauthClientConfiguration)
 			//
 			r.Group(func(r chi.Router) {
 				r.Use(func(next http.Handler) http.Handler {
@@ -131,6 +145,7 @@ func (ip *IdentitiyProvider) Handler() http.Handler {
 			})
 
 			r.Get("/discovery/keys", ip.OauthDiscoveryKeys)
+			r.Get("/check_session", ip.OauthCheckSession)
 		})
 
 		r.Route("/.well-known", func(r chi.Router) {
@@ -0,0 +1,34 @@
+package openid
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+// SecurityEvent describes a suspicious request, like the replay of an authorization code
+type SecurityEvent struct {
+	Type      enums.SecurityEventType
+	Time      time.Time
+	ClientID  string
+	UserID    string
+	SessionID string
+	// Description explains the action, which was taken because of the event
+	Description string
+}
+
+// SecurityEventFunc receives the security events of the identity provider
+type SecurityEventFunc func(ctx context.Context, event SecurityEvent)
+
+func (ip *IdentitiyProvider) emitSecurityEvent(ctx context.Context, event SecurityEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if ip.securityEvents == nil {
+		log.Println("security event", event.Type, "client", event.ClientID, "user", event.UserID, "session", event.SessionID, event.Description)
+		return
+	}
+	ip.securityEvents(ctx, event)
+}
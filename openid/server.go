@@ -2,6 +2,7 @@ package openid
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -29,6 +30,14 @@ func (ip *IdentitiyProvider) StartServer(ctx context.Context, addr string) {
 		Addr:    addr,
 	}
 
+	if ip.tlsCertFile != "" {
+		// The certificates are verified by the client authentication depending on the registered method,
+		// because self-signed certificates would be rejected by the handshake
+		server.TLSConfig = &tls.Config{
+			ClientAuth: tls.RequestClientCert,
+		}
+	}
+
 	context.AfterFunc(ctx, func() {
 		ctx := context.Background()
 		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
@@ -39,7 +48,13 @@ func (ip *IdentitiyProvider) StartServer(ctx context.Context, addr string) {
 		}
 	})
 
-	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	var err error
+	if ip.tlsCertFile != "" {
+		err = server.ListenAndServeTLS(ip.tlsCertFile, ip.tlsKeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		// TODO: Logging package from context
 		fmt.Println(err)
 	}
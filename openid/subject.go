@@ -0,0 +1,31 @@
+package openid
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/token"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+)
+
+// subjectIdentifier returns the subject identifier of the user, which is sent to the client
+func (ip *IdentitiyProvider) subjectIdentifier(ctx context.Context, reg client.Client, userID string) string {
+	if reg.SubjectType != enums.SubjectTypePairwise {
+		return userID
+	}
+	return jose.GetJose(ctx).PairwiseSubject(reg.SectorIdentifier(), userID)
+}
+
+// tokenSession returns the session of the token.
+// The subject of the token can be pairwise, so the session is the only way back to the internal user id.
+func (ip *IdentitiyProvider) tokenSession(ctx context.Context, t jwt.Token) (token.Session, error) {
+	var sessionID string
+	t.Get(enums.ClaimSid.String(), &sessionID)
+	if sessionID == "" {
+		return token.Session{}, errors.New("the token belongs to no user session")
+	}
+	return ip.tokenStore.GetSession(ctx, sessionID)
+}
@@ -0,0 +1,36 @@
+package openid
+
+import (
+	"testing"
+
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+func TestPairwiseClientsRequireSalt(t *testing.T) {
+	pairwise := client.Client{ClientID: "pairwise", SubjectType: enums.SubjectTypePairwise}
+
+	if _, err := tryNewTestProvider(t, WithClients(pairwise)); err == nil {
+		t.Fatal("expected a pairwise client without salt to be rejected")
+	}
+	if _, err := tryNewTestProvider(t, WithClients(pairwise), WithPairwiseSubjectSalt([]byte("salt"))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPairwiseSubject(t *testing.T) {
+	ip := newTestProvider(t, WithPairwiseSubjectSalt([]byte("salt")))
+	j := jose.GetJose(testContext(t, ip))
+
+	if j.PairwiseSubject("sector", "user") != j.PairwiseSubject("sector", "user") {
+		t.Error("expected the pairwise subject to be stable")
+	}
+	// The concatenation of both pairs is the same, so the fields have to be separated
+	if j.PairwiseSubject("sector.example", "user") == j.PairwiseSubject("sector.exampleu", "ser") {
+		t.Error("expected different pairs of sector and subject to have different subjects")
+	}
+	if j.PairwiseSubject("sector", "user") == j.PairwiseSubject("other-sector", "user") {
+		t.Error("expected the sectors to have different subjects")
+	}
+}
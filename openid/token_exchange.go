@@ -0,0 +1,292 @@
+package openid
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/akatranlp/go-pkg/its"
+	"github.com/akatranlp/sentinel/account"
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/token"
+	"github.com/akatranlp/sentinel/utils"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+)
+
+// audienceParam can be repeated, so it is not part of the decoded form values
+const audienceParam = "audience"
+
+var ErrTokenNotIssuedToClient = errors.New("the token was not issued to the client")
+
+// handleTokenExchange exchanges a token of the user for a downscoped access token of another audience (RFC 8693).
+// With requested_issuer the stored token of the user from this provider is returned instead.
+func (ip *IdentitiyProvider) handleTokenExchange(ctx context.Context, reg client.Client, req types.TokenRequest) (types.TokenResponse, *TokenError) {
+	policy := reg.TokenExchangePolicy
+	if policy == nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeUnauthorizedClient,
+			ErrorDescription: "the client is not allowed to exchange tokens",
+		}
+	}
+
+	if !policy.AllowsSubjectTokenType(req.SubjectTokenType) {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: "the client is not allowed to exchange tokens of this type",
+		}
+	}
+
+	subjectToken, err := ip.parseExchangeToken(ctx, reg, req.SubjectToken, req.SubjectTokenType, req.Confirmation)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	sess, err := ip.tokenSession(ctx, subjectToken)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorDescription: err.Error(),
+		}
+	}
+	if req.SubjectTokenType == enums.OauthTokenTypeRefreshToken && sess.RefreshJTI != utils.Bang(subjectToken.JwtID()) {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorDescription: "token is revoked",
+		}
+	}
+
+	if req.RequestedIssuer != "" {
+		return ip.exchangeProviderToken(ctx, policy, sess, req)
+	}
+
+	if req.RequestedTokenType != "" && req.RequestedTokenType != enums.OauthTokenTypeAccessToken {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: enums.ErrInvalidOauthTokenType.Error(),
+		}
+	}
+
+	actor, tokenErr := ip.exchangeActor(ctx, reg, policy, subjectToken, req)
+	if tokenErr != nil {
+		return types.TokenResponse{}, tokenErr
+	}
+
+	// The exchanged token can only be downscoped
+	var scopeStr string
+	subjectToken.Get(enums.ClaimScope.String(), &scopeStr)
+
+	var scopes enums.Scopes
+	scopes.UnmarshalText([]byte(scopeStr))
+
+	if len(req.Scope) > 0 {
+		if !its.All(slices.Values(req.Scope), func(scope enums.Scope) bool { return slices.Contains(scopes, scope) }) {
+			return types.TokenResponse{}, &TokenError{
+				ErrorType: TokenErrorTypeInvalidScope,
+			}
+		}
+		scopes = req.Scope
+	}
+
+	for _, audience := range append(slices.Clone(req.Audience), req.Resource...) {
+		if !policy.AllowsAudience(audience) {
+			return types.TokenResponse{}, &TokenError{
+				ErrorType:        TokenErrorTypeInvalidTarget,
+				ErrorDescription: "the client is not allowed to request tokens for " + audience,
+			}
+		}
+	}
+
+	resources, err := ip.lookupResources(ctx, reg.ClientID, req.Resource)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		}
+	}
+	resourceAud, resourceScope, err := resourceAudience(resources, scopes)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		}
+	}
+	if len(resourceScope) > 0 {
+		scopes = resourceScope
+	}
+
+	j := jose.GetJose(ctx)
+
+	currTime := time.Now()
+	accessToken, signedAccessToken, err := j.CreateAccessToken(jose.TokenCreateArg{
+		CurrTime:     currTime,
+		Subject:      ip.subjectIdentifier(ctx, reg, sess.UserID),
+		Audience:     []string{reg.ClientID},
+		SessionID:    sess.SessionID,
+		Scope:        scopes,
+		ClientID:     reg.ClientID,
+		Resources:    append(slices.Clone(req.Audience), resourceAud...),
+		AuthTime:     sess.AuthTime,
+		ACR:          sess.ACR,
+		AMR:          sess.AMR,
+		Actor:        actor,
+		Confirmation: req.Confirmation,
+	})
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeServerError,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	return types.TokenResponse{
+		AccessToken:     signedAccessToken,
+		ExpiresIn:       int(utils.Bang(accessToken.Expiration()).Sub(currTime) / time.Second),
+		TokenType:       tokenType(req.Confirmation),
+		Scope:           scopes,
+		IssuedTokenType: enums.OauthTokenTypeAccessToken,
+	}, nil
+}
+
+// parseExchangeToken parses a subject or actor token.
+// Only tokens which were issued to the client or for it as audience can be exchanged.
+// Bound tokens can only be exchanged with the proof of their key, like at the userinfo endpoint.
+func (ip *IdentitiyProvider) parseExchangeToken(ctx context.Context, reg client.Client, signedToken string, tokenType enums.OauthTokenType, cnf *types.Confirmation) (jwt.Token, error) {
+	j := jose.GetJose(ctx)
+
+	var parsedToken jwt.Token
+	var err error
+	switch tokenType {
+	case enums.OauthTokenTypeAccessToken:
+		parsedToken, err = j.ParseAccessToken(signedToken)
+	case enums.OauthTokenTypeRefreshToken, enums.OauthTokenTypeIdToken:
+		parsedToken, err = jwt.ParseString(
+			signedToken,
+			jwt.WithKeySet(j.PublicKeys()),
+			jwt.WithIssuer(j.Issuer()),
+			jwt.WithClaimValue(enums.ClaimTokenType.String(), tokenType.String()),
+		)
+	default:
+		return nil, enums.ErrInvalidOauthTokenType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if jose.AccessTokenClientID(parsedToken) != reg.ClientID && !slices.Contains(utils.Bang(parsedToken.Audience()), reg.ClientID) {
+		return nil, ErrTokenNotIssuedToClient
+	}
+	if err := checkTokenBinding(parsedToken, cnf); err != nil {
+		return nil, err
+	}
+	return parsedToken, nil
+}
+
+// exchangeActor returns the act claim of the exchanged token.
+// With an actor token the client acts on behalf of the subject and the actor is put in front of the previous actors,
+// without it the client impersonates the subject.
+func (ip *IdentitiyProvider) exchangeActor(ctx context.Context, reg client.Client, policy *client.TokenExchangePolicy, subjectToken jwt.Token, req types.TokenRequest) (map[string]any, *TokenError) {
+	var previous map[string]any
+	subjectToken.Get(enums.ClaimAct.String(), &previous)
+
+	if req.ActorToken == "" {
+		if req.ActorTokenType != "" {
+			return nil, &TokenError{
+				ErrorType:        TokenErrorTypeInvalidRequest,
+				ErrorDescription: "actor_token_type is only allowed with an actor_token",
+			}
+		}
+		if !policy.Impersonation {
+			return nil, &TokenError{
+				ErrorType:        TokenErrorTypeUnauthorizedClient,
+				ErrorDescription: "the client is not allowed to impersonate the subject",
+			}
+		}
+		return previous, nil
+	}
+
+	if !policy.Delegation {
+		return nil, &TokenError{
+			ErrorType:        TokenErrorTypeUnauthorizedClient,
+			ErrorDescription: "the client is not allowed to act on behalf of the subject",
+		}
+	}
+	if req.ActorTokenType == "" {
+		return nil, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: "actor_token_type is required with an actor_token",
+		}
+	}
+
+	actorToken, err := ip.parseExchangeToken(ctx, reg, req.ActorToken, req.ActorTokenType, req.Confirmation)
+	if err != nil {
+		return nil, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidGrant,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	act := map[string]any{enums.ClaimSub.String(): utils.Bang(actorToken.Subject())}
+	if previous != nil {
+		act[enums.ClaimAct.String()] = previous
+	}
+	return act, nil
+}
+
+// exchangeProviderToken returns the token of the user from the requested provider
+func (ip *IdentitiyProvider) exchangeProviderToken(ctx context.Context, policy *client.TokenExchangePolicy, sess token.Session, req types.TokenRequest) (types.TokenResponse, *TokenError) {
+	if req.RequestedTokenType != "" && req.RequestedTokenType != enums.OauthTokenTypeAccessToken {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidRequest,
+			ErrorDescription: enums.ErrInvalidOauthTokenType.Error(),
+		}
+	}
+
+	if !policy.AllowsProvider(req.RequestedIssuer) {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: "the client is not allowed to request tokens of this provider",
+		}
+	}
+
+	acc, err := ip.userStore.GetAccountByProvider(ctx, account.UserID(sess.UserID), req.RequestedIssuer)
+	if err != nil {
+		return types.TokenResponse{}, &TokenError{
+			ErrorType:        TokenErrorTypeInvalidTarget,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	if accountExpiring(acc) {
+		acc, err = ip.refreshAccount(ctx, acc)
+		if errors.Is(err, ErrAccountRelinkRequired) {
+			return types.TokenResponse{}, &TokenError{
+				ErrorType:        TokenErrorTypeInvalidGrant,
+				ErrorDescription: err.Error(),
+			}
+		} else if err != nil {
+			return types.TokenResponse{}, &TokenError{
+				ErrorType:        TokenErrorTypeServerError,
+				ErrorDescription: err.Error(),
+			}
+		}
+	}
+
+	var expiresIn int
+	if !acc.Expiry.IsZero() {
+		expiresIn = int(time.Until(acc.Expiry) / time.Second)
+	}
+
+	return types.TokenResponse{
+		AccessToken:     acc.AccessToken,
+		ExpiresIn:       expiresIn,
+		TokenType:       acc.TokenType,
+		IssuedTokenType: enums.OauthTokenTypeAccessToken,
+	}, nil
+}
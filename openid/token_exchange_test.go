@@ -0,0 +1,215 @@
+package openid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatranlp/sentinel/account"
+	"github.com/akatranlp/sentinel/client"
+	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+	"github.com/akatranlp/sentinel/token"
+	"github.com/google/uuid"
+)
+
+// newTestExchangeToken creates an access token of the session, which was issued to the client
+func newTestExchangeToken(t *testing.T, ctx context.Context, subject, sessionID string, cnf *types.Confirmation) string {
+	t.Helper()
+	_, signedToken, err := jose.GetJose(ctx).CreateAccessToken(jose.TokenCreateArg{
+		CurrTime:     time.Now(),
+		Subject:      subject,
+		Audience:     []string{"client"},
+		SessionID:    sessionID,
+		Scope:        enums.Scopes{enums.ScopeOpenid, enums.ScopeProfile},
+		ClientID:     "client",
+		Confirmation: cnf,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signedToken
+}
+
+func TestTokenExchange(t *testing.T) {
+	ip := newTestProvider(t)
+	ctx := testContext(t, ip)
+
+	user, err := ip.userStore.GetOrCreateUserFromAccount(ctx, account.Account{
+		AccountID:   account.AccountID{Provider: "upstream", ProviderID: "upstream-user"},
+		AccessToken: "upstream-token",
+		TokenType:   "Bearer",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionID := uuid.NewString()
+	if err = ip.tokenStore.SetSession(ctx, token.Session{
+		SessionID: sessionID,
+		UserID:    string(user.UserID),
+		ClientID:  "client",
+		Expiry:    time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cnf := &types.Confirmation{JKT: "client-key-thumbprint"}
+	subjectToken := newTestExchangeToken(t, ctx, string(user.UserID), sessionID, nil)
+	boundSubjectToken := newTestExchangeToken(t, ctx, string(user.UserID), sessionID, cnf)
+	actorToken := newTestExchangeToken(t, ctx, "actor", uuid.NewString(), nil)
+	boundActorToken := newTestExchangeToken(t, ctx, "actor", uuid.NewString(), cnf)
+
+	policy := func(modify func(*client.TokenExchangePolicy)) *client.TokenExchangePolicy {
+		p := &client.TokenExchangePolicy{
+			SubjectTokenTypes: []enums.OauthTokenType{enums.OauthTokenTypeAccessToken},
+		}
+		if modify != nil {
+			modify(p)
+		}
+		return p
+	}
+	impersonation := func(p *client.TokenExchangePolicy) { p.Impersonation = true }
+	delegation := func(p *client.TokenExchangePolicy) { p.Delegation = true }
+	withActor := func(actorToken string) func(*types.TokenRequest) {
+		return func(req *types.TokenRequest) {
+			req.ActorToken = actorToken
+			req.ActorTokenType = enums.OauthTokenTypeAccessToken
+		}
+	}
+
+	tests := []struct {
+		name    string
+		policy  *client.TokenExchangePolicy
+		modify  func(*types.TokenRequest)
+		wantErr TokenErrorType
+		// wantToken is the expected access token, if it is not issued by the identity provider itself
+		wantToken string
+	}{
+		{
+			name:    "without policy",
+			wantErr: TokenErrorTypeUnauthorizedClient,
+		},
+		{
+			name:    "subject token type not allowed",
+			policy:  policy(impersonation),
+			modify:  func(req *types.TokenRequest) { req.SubjectTokenType = enums.OauthTokenTypeIdToken },
+			wantErr: TokenErrorTypeInvalidRequest,
+		},
+		{
+			name:   "impersonation",
+			policy: policy(impersonation),
+		},
+		{
+			name:    "impersonation not allowed",
+			policy:  policy(delegation),
+			wantErr: TokenErrorTypeUnauthorizedClient,
+		},
+		{
+			name:   "delegation",
+			policy: policy(delegation),
+			modify: withActor(actorToken),
+		},
+		{
+			name:    "delegation not allowed",
+			policy:  policy(impersonation),
+			modify:  withActor(actorToken),
+			wantErr: TokenErrorTypeUnauthorizedClient,
+		},
+		{
+			name:    "actor token without type",
+			policy:  policy(delegation),
+			modify:  func(req *types.TokenRequest) { req.ActorToken = actorToken },
+			wantErr: TokenErrorTypeInvalidRequest,
+		},
+		{
+			name: "allowed audience",
+			policy: policy(func(p *client.TokenExchangePolicy) {
+				impersonation(p)
+				p.Audiences = []string{"api"}
+			}),
+			modify: func(req *types.TokenRequest) { req.Audience = []string{"api"} },
+		},
+		{
+			name: "audience not allowed",
+			policy: policy(func(p *client.TokenExchangePolicy) {
+				impersonation(p)
+				p.Audiences = []string{"api"}
+			}),
+			modify:  func(req *types.TokenRequest) { req.Audience = []string{"other-api"} },
+			wantErr: TokenErrorTypeInvalidTarget,
+		},
+		{
+			name:      "allowed provider",
+			policy:    policy(func(p *client.TokenExchangePolicy) { p.Providers = []string{"upstream"} }),
+			modify:    func(req *types.TokenRequest) { req.RequestedIssuer = "upstream" },
+			wantToken: "upstream-token",
+		},
+		{
+			name:    "provider not allowed",
+			policy:  policy(func(p *client.TokenExchangePolicy) { p.Providers = []string{"other"} }),
+			modify:  func(req *types.TokenRequest) { req.RequestedIssuer = "upstream" },
+			wantErr: TokenErrorTypeInvalidTarget,
+		},
+		{
+			name:   "bound subject token with proof",
+			policy: policy(impersonation),
+			modify: func(req *types.TokenRequest) {
+				req.SubjectToken = boundSubjectToken
+				req.Confirmation = cnf
+			},
+		},
+		{
+			name:    "bound subject token without proof",
+			policy:  policy(impersonation),
+			modify:  func(req *types.TokenRequest) { req.SubjectToken = boundSubjectToken },
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+		{
+			name:   "bound subject token with another key",
+			policy: policy(impersonation),
+			modify: func(req *types.TokenRequest) {
+				req.SubjectToken = boundSubjectToken
+				req.Confirmation = &types.Confirmation{JKT: "other-key-thumbprint"}
+			},
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+		{
+			name:    "bound actor token without proof",
+			policy:  policy(delegation),
+			modify:  withActor(boundActorToken),
+			wantErr: TokenErrorTypeInvalidGrant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := types.TokenRequest{
+				ClientID:         "client",
+				GrantType:        enums.GrantTypeTokenExchange,
+				SubjectToken:     subjectToken,
+				SubjectTokenType: enums.OauthTokenTypeAccessToken,
+			}
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+
+			res, tokenErr := ip.handleTokenExchange(ctx, client.Client{ClientID: "client", TokenExchangePolicy: tt.policy}, req)
+			if tt.wantErr != "" {
+				if tokenErr == nil || tokenErr.ErrorType != tt.wantErr {
+					t.Fatalf("expected error %s, got %v", tt.wantErr, tokenErr)
+				}
+				return
+			}
+			if tokenErr != nil {
+				t.Fatalf("unexpected error %s: %s", tokenErr.ErrorType, tokenErr.ErrorDescription)
+			}
+			if tt.wantToken != "" && res.AccessToken != tt.wantToken {
+				t.Errorf("expected the access token %s, got %s", tt.wantToken, res.AccessToken)
+			}
+			if res.AccessToken == "" || res.IssuedTokenType != enums.OauthTokenTypeAccessToken {
+				t.Errorf("expected an access token, got %+v", res)
+			}
+		})
+	}
+}
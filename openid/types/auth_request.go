@@ -17,17 +17,21 @@ type AuthRequest struct {
 	CodeChallenge       string                    `mapstructure:"code_challenge"`
 	State               string                    `mapstructure:"state"`
 	Nonce               string                    `mapstructure:"nonce"`
+	// ACRValues are the space separated authentication context classes the client prefers
+	ACRValues string `mapstructure:"acr_values"`
+	// Resource are the resource indicators of the access tokens (RFC 8707)
+	Resource []string `mapstructure:"-"`
+	// Claims selects the claims of the id token and the userinfo response
+	Claims ClaimsRequest `mapstructure:"claims"`
 	// optional, check if i want to implement them
 	Display enums.Display `mapstructure:"display"`
 	Prompt  enums.Prompt  `mapstructure:"prompt"`
 	MaxAge  *int          `mapstructure:"max_age"`
 }
 
+// IsValid checks the parameters of the request.
+// The scopes are not checked, because the identity provider can accept custom scopes.
 func (a *AuthRequest) IsValid() error {
-	if !a.Scope.IsValid() {
-		return enums.ErrInvalidScope
-	}
-
 	if !a.ResponseType.IsValid() {
 		return enums.ErrInvalidResponseType
 	}
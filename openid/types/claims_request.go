@@ -0,0 +1,59 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// ClaimsRequest is the claims parameter of an authorization request (OpenID Connect Core 5.5).
+// The members are keyed by the claim name, unknown claims are kept and ignored later.
+type ClaimsRequest struct {
+	UserInfo map[string]ClaimRequest `json:"userinfo,omitempty"`
+	IDToken  map[string]ClaimRequest `json:"id_token,omitempty"`
+}
+
+// ClaimRequest is empty if the claim is requested in the default manner
+type ClaimRequest struct {
+	Essential bool  `json:"essential,omitempty"`
+	Value     any   `json:"value,omitempty"`
+	Values    []any `json:"values,omitempty"`
+}
+
+type claimsRequest ClaimsRequest
+
+func (c *ClaimsRequest) UnmarshalText(text []byte) error {
+	// MarshalText writes nothing for an empty request
+	if len(text) == 0 {
+		*c = ClaimsRequest{}
+		return nil
+	}
+	var req claimsRequest
+	if err := json.Unmarshal(text, &req); err != nil {
+		return err
+	}
+	*c = ClaimsRequest(req)
+	return nil
+}
+
+func (c ClaimsRequest) MarshalText() ([]byte, error) {
+	if c.IsZero() {
+		return nil, nil
+	}
+	return json.Marshal(claimsRequest(c))
+}
+
+func (c ClaimsRequest) IsZero() bool {
+	return len(c.UserInfo) == 0 && len(c.IDToken) == 0
+}
+
+// Matches reports if the value fulfills the requested value or values of the claim
+func (c ClaimRequest) Matches(value any) bool {
+	if c.Value != nil && !reflect.DeepEqual(c.Value, value) {
+		return false
+	}
+	if len(c.Values) > 0 && !slices.ContainsFunc(c.Values, func(v any) bool { return reflect.DeepEqual(v, value) }) {
+		return false
+	}
+	return true
+}
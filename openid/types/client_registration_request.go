@@ -0,0 +1,29 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+type ClientRegistrationRequest struct {
+	// ClientID and ClientSecret are only sent when the client updates its registration
+	ClientID                           string                   `json:"client_id,omitzero"`
+	ClientSecret                       string                   `json:"client_secret,omitzero"`
+	ClientName                         string                   `json:"client_name,omitzero"`
+	RedirectURIs                       []string                 `json:"redirect_uris,omitzero"`
+	PostLogoutRedirectURIs             []string                 `json:"post_logout_redirect_uris,omitzero"`
+	GrantTypes                         []enums.GrantType        `json:"grant_types,omitzero"`
+	ResponseTypes                      []enums.ResponseType     `json:"response_types,omitzero"`
+	Scope                              enums.Scopes             `json:"scope,omitzero"`
+	TokenEndpointAuthMethod            enums.EndpointAuthMethod `json:"token_endpoint_auth_method,omitzero"`
+	JWKS                               json.RawMessage          `json:"jwks,omitzero"`
+	JWKSURI                            string                   `json:"jwks_uri,omitzero"`
+	TLSClientAuthSubjectDN             string                   `json:"tls_client_auth_subject_dn,omitzero"`
+	SubjectType                        enums.SubjectType        `json:"subject_type,omitzero"`
+	SectorIdentifierURI                string                   `json:"sector_identifier_uri,omitzero"`
+	FrontchannelLogoutURI              string                   `json:"frontchannel_logout_uri,omitzero"`
+	BackchannelLogoutURI               string                   `json:"backchannel_logout_uri,omitzero"`
+	BackchannelLogoutSessionRequired   bool                     `json:"backchannel_logout_session_required,omitzero"`
+	RequirePushedAuthorizationRequests bool                     `json:"require_pushed_authorization_requests,omitzero"`
+}
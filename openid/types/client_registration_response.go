@@ -0,0 +1,30 @@
+package types
+
+import (
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+type ClientRegistrationResponse struct {
+	ClientID                           string                   `json:"client_id"`
+	ClientSecret                       string                   `json:"client_secret,omitzero"`
+	ClientIDIssuedAt                   int64                    `json:"client_id_issued_at,omitzero"`
+	ClientSecretExpiresAt              int64                    `json:"client_secret_expires_at"`
+	RegistrationAccessToken            string                   `json:"registration_access_token,omitzero"`
+	RegistrationClientURI              string                   `json:"registration_client_uri"`
+	ClientName                         string                   `json:"client_name,omitzero"`
+	RedirectURIs                       []string                 `json:"redirect_uris,omitzero"`
+	PostLogoutRedirectURIs             []string                 `json:"post_logout_redirect_uris,omitzero"`
+	GrantTypes                         []enums.GrantType        `json:"grant_types,omitzero"`
+	Scope                              enums.Scopes             `json:"scope,omitzero"`
+	TokenEndpointAuthMethod            enums.EndpointAuthMethod `json:"token_endpoint_auth_method"`
+	JWKS                               jwk.Set                  `json:"jwks,omitempty"`
+	JWKSURI                            string                   `json:"jwks_uri,omitzero"`
+	TLSClientAuthSubjectDN             string                   `json:"tls_client_auth_subject_dn,omitzero"`
+	SubjectType                        enums.SubjectType        `json:"subject_type"`
+	SectorIdentifierURI                string                   `json:"sector_identifier_uri,omitzero"`
+	FrontchannelLogoutURI              string                   `json:"frontchannel_logout_uri,omitzero"`
+	BackchannelLogoutURI               string                   `json:"backchannel_logout_uri,omitzero"`
+	BackchannelLogoutSessionRequired   bool                     `json:"backchannel_logout_session_required,omitzero"`
+	RequirePushedAuthorizationRequests bool                     `json:"require_pushed_authorization_requests,omitzero"`
+}
@@ -0,0 +1,7 @@
+package types
+
+// ClientSession is a token session of a client which was started in the browser session of the user
+type ClientSession struct {
+	ClientID  string
+	SessionID string
+}
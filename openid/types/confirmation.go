@@ -0,0 +1,9 @@
+package types
+
+// Confirmation is the cnf claim, which binds a token to a key of the client
+type Confirmation struct {
+	// X5tS256 is the SHA-256 thumbprint of the client certificate (RFC 8705)
+	X5tS256 string `json:"x5t#S256,omitzero"`
+	// JKT is the SHA-256 thumbprint of the DPoP key (RFC 9449)
+	JKT string `json:"jkt,omitzero"`
+}
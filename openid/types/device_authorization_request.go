@@ -0,0 +1,9 @@
+package types
+
+import "github.com/akatranlp/sentinel/openid/enums"
+
+type DeviceAuthorizationRequest struct {
+	ClientID     string       `mapstructure:"client_id"`
+	ClientSecret string       `mapstructure:"client_secret"`
+	Scope        enums.Scopes `mapstructure:"scope"`
+}
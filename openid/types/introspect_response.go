@@ -15,4 +15,6 @@ type IntrospectResponse struct {
 	Audience   []string     `json:"aud,omitzero"`
 	Issuer     string       `json:"iss,omitzero"`
 	Jti        string       `json:"jti,omitzero"`
+
+	Confirmation *Confirmation `json:"cnf,omitzero"`
 }
@@ -2,7 +2,16 @@ package types
 
 type LogoutRequest struct {
 	IDTokenHint           string `mapstructure:"id_token_hint"`
+	LogoutHint            string `mapstructure:"logout_hint"`
 	PostLogoutRedirectURI string `mapstructure:"post_logout_redirect_uri"`
 	ClientID              string `mapstructure:"client_id"`
 	State                 string `mapstructure:"state"`
+	UILocales             string `mapstructure:"ui_locales"`
+}
+
+// PendingLogout is a validated logout request, which waits for the confirmation of the user
+type PendingLogout struct {
+	SessionID string
+	ClientID  string
+	Redirect  string
 }
@@ -1,6 +1,28 @@
 package types
 
-import "github.com/akatranlp/sentinel/openid/enums"
+import (
+	"slices"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+// UILocalesSupported are the languages the pages of the provider are available in
+var UILocalesSupported = []string{"en"}
+
+// idTokenClaims are the claims of the id token, which do not depend on the scopes.
+// The user claims are added by the release policy of the scopes.
+var idTokenClaims = []enums.Claim{
+	enums.ClaimIss,
+	enums.ClaimSub,
+	enums.ClaimAud,
+	enums.ClaimExp,
+	enums.ClaimIat,
+	enums.ClaimAuthTime,
+	enums.ClaimNonce,
+	enums.ClaimAcr,
+	enums.ClaimAmr,
+	enums.ClaimSid,
+}
 
 type OpenIDConfiguration struct {
 	Issuer                string `json:"issuer"`
@@ -11,19 +33,41 @@ type OpenIDConfiguration struct {
 	UserInfoEndpoint      string `json:"userinfo_endpoint"`
 	JWKSURI               string `json:"jwks_uri"`
 	EndSessionEndpoint    string `json:"end_session_endpoint"`
+	CheckSessionIframe    string `json:"check_session_iframe"`
+
+	DeviceAuthorizationEndpoint        string `json:"device_authorization_endpoint"`
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
+	RegistrationEndpoint               string `json:"registration_endpoint,omitzero"`
+
+	ScopesSupported                            []enums.Scope                  `json:"scopes_supported"`
+	ResponseTypesSupported                     []enums.ResponseType           `json:"response_types_supported"`
+	ResponseModesSupported                     []enums.ResponseMode           `json:"response_modes_supported"`
+	GrantTypesSupported                        []enums.GrantType              `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported          []enums.EndpointAuthMethod     `json:"token_endpoint_auth_methods_supported"`
+	IntrospectionEndpointAuthMethodsSupported  []enums.EndpointAuthMethod     `json:"introspection_endpoint_auth_methods_supported"`
+	RevocationEndpointAuthMethodsSupported     []enums.EndpointAuthMethod     `json:"revocation_endpoint_auth_methods_supported"`
+	SubjectTypesSupported                      []enums.SubjectType            `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported           []enums.IDTokenSigningAlgValue `json:"id_token_signing_alg_values_supported"`
+	ClaimTypesSupported                        []enums.ClaimType              `json:"claim_types_supported"`
+	ClaimsSupported                            []enums.Claim                  `json:"claims_supported"`
+	CodeChallengeMethodsSupported              []enums.CodeChallengeMethod    `json:"code_challenge_methods_supported"`
+	RequestObjectSigningAlgValuesSupported     []enums.SigningAlgValue        `json:"request_object_signing_alg_values_supported"`
+	TokenEndpointAuthSigningAlgValuesSupported []enums.SigningAlgValue        `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+
+	UILocalesSupported []string `json:"ui_locales_supported"`
+	ACRValuesSupported []string `json:"acr_values_supported,omitempty"`
 
-	ScopesSupported                           []enums.Scope                  `json:"scopes_supported"`
-	ResponseTypesSupported                    []enums.ResponseType           `json:"response_types_supported"`
-	ResponseModesSupported                    []enums.ResponseMode           `json:"response_modes_supported"`
-	GrantTypesSupported                       []enums.GrantType              `json:"grant_types_supported"`
-	TokenEndpointAuthMethodsSupported         []enums.EndpointAuthMethod     `json:"token_endpoint_auth_methods_supported"`
-	IntrospectionEndpointAuthMethodsSupported []enums.EndpointAuthMethod     `json:"introspection_endpoint_auth_methods_supported"`
-	RevocationEndpointAuthMethodsSupported    []enums.EndpointAuthMethod     `json:"revocation_endpoint_auth_methods_supported"`
-	SubjectTypesSupported                     []enums.SubjectType            `json:"subject_types_supported"`
-	IDTokenSigningAlgValuesSupported          []enums.IDTokenSigningAlgValue `json:"id_token_signing_alg_values_supported"`
-	ClaimTypesSupported                       []enums.ClaimType              `json:"claim_types_supported"`
-	ClaimsSupported                           []enums.Claim                  `json:"claims_supported"`
-	CodeChallengeMethodsSupported             []enums.CodeChallengeMethod    `json:"code_challenge_methods_supported"`
+	ClaimsParameterSupported     bool `json:"claims_parameter_supported"`
+	RequestParameterSupported    bool `json:"request_parameter_supported"`
+	RequestURIParameterSupported bool `json:"request_uri_parameter_supported"`
+
+	FrontchannelLogoutSupported        bool `json:"frontchannel_logout_supported"`
+	FrontchannelLogoutSessionSupported bool `json:"frontchannel_logout_session_supported"`
+	BackchannelLogoutSupported         bool `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported  bool `json:"backchannel_logout_session_supported"`
+
+	TLSClientCertificateBoundAccessTokens bool                    `json:"tls_client_certificate_bound_access_tokens"`
+	DPoPSigningAlgValuesSupported         []enums.SigningAlgValue `json:"dpop_signing_alg_values_supported"`
 }
 
 func CreateOIDCConfig(origin string) OpenIDConfiguration {
@@ -36,6 +80,10 @@ func CreateOIDCConfig(origin string) OpenIDConfiguration {
 		UserInfoEndpoint:      origin + "/oauth/userinfo",
 		JWKSURI:               origin + "/oauth/discovery/keys",
 		EndSessionEndpoint:    origin + "/oauth/logout",
+		CheckSessionIframe:    origin + "/oauth/check_session",
+
+		DeviceAuthorizationEndpoint:        origin + "/oauth/device_authorization",
+		PushedAuthorizationRequestEndpoint: origin + "/oauth/par",
 
 		ScopesSupported:                           enums.ScopeValues(),
 		ResponseTypesSupported:                    enums.ResponseTypeValues(),
@@ -47,7 +95,22 @@ func CreateOIDCConfig(origin string) OpenIDConfiguration {
 		SubjectTypesSupported:                     enums.SubjectTypeValues(),
 		IDTokenSigningAlgValuesSupported:          enums.IDTokenSigningAlgValueValues(),
 		ClaimTypesSupported:                       enums.ClaimTypeValues(),
-		ClaimsSupported:                           enums.ClaimValues(),
+		ClaimsSupported:                           slices.Clone(idTokenClaims),
 		CodeChallengeMethodsSupported:             enums.CodeChallengeMethodValues(),
+		RequestObjectSigningAlgValuesSupported:    slices.DeleteFunc(enums.SigningAlgValueValues(), enums.SigningAlgValue.IsSymmetric),
+
+		UILocalesSupported: UILocalesSupported,
+
+		ClaimsParameterSupported:  true,
+		RequestParameterSupported: true,
+		// Only request uris of the pushed authorization request endpoint are supported
+		RequestURIParameterSupported: false,
+
+		FrontchannelLogoutSupported:        true,
+		FrontchannelLogoutSessionSupported: true,
+		BackchannelLogoutSupported:         true,
+		BackchannelLogoutSessionSupported:  true,
+
+		DPoPSigningAlgValuesSupported: slices.DeleteFunc(enums.SigningAlgValueValues(), enums.SigningAlgValue.IsSymmetric),
 	}
 }
@@ -14,9 +14,21 @@ type TokenRequest struct {
 	// Refresh Token GrantType
 	RefreshToken string `mapstructure:"refresh_token"`
 	// Token Exchange GrantType
-	RequestedTokenType  enums.OauthTokenType `mapstructure:"requested_token_type"`
-	RequestedIssuer     string               `mapstructure:"requested_issuer"`
-	SubjectToken        string               `mapstructure:"subject_token"`
-	SubjectTokenType    enums.OauthTokenType `mapstructure:"subject_token_type"`
-	TokenExchangeSecret string               `mapstructure:"__token_exchange_secret__"`
+	RequestedTokenType enums.OauthTokenType `mapstructure:"requested_token_type"`
+	// RequestedIssuer is the slug of a provider, whose token of the user is requested
+	RequestedIssuer  string               `mapstructure:"requested_issuer"`
+	SubjectToken     string               `mapstructure:"subject_token"`
+	SubjectTokenType enums.OauthTokenType `mapstructure:"subject_token_type"`
+	ActorToken       string               `mapstructure:"actor_token"`
+	ActorTokenType   enums.OauthTokenType `mapstructure:"actor_token_type"`
+	// Audience are the logical names of the targets, the parameter can be repeated so it is not decoded
+	Audience []string `mapstructure:"-"`
+	// Device Code GrantType
+	DeviceCode string `mapstructure:"device_code"`
+
+	// Resource are the resource indicators of the access token (RFC 8707)
+	Resource []string `mapstructure:"-"`
+
+	// Confirmation is not part of the form, it is set from the connection of the client
+	Confirmation *Confirmation `mapstructure:"-"`
 }
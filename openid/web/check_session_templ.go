@@ -0,0 +1,48 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.865
+package web
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+func CheckSession(cookieName string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html><head><meta charset=\"UTF-8\"><title>Check Session</title></head><body>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templ.JSONScript("browser-state-cookie", cookieName).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<script>\n\t\t\t\t(function () {\n\t\t\t\t\tvar cookieName = JSON.parse(document.getElementById(\"browser-state-cookie\").textContent);\n\n\t\t\t\t\tfunction getBrowserState() {\n\t\t\t\t\t\tvar cookies = document.cookie.split(\";\");\n\t\t\t\t\t\tfor (var i = 0; i < cookies.length; i++) {\n\t\t\t\t\t\t\tvar cookie = cookies[i].trim();\n\t\t\t\t\t\t\tif (cookie.indexOf(cookieName + \"=\") === 0) {\n\t\t\t\t\t\t\t\treturn decodeURIComponent(cookie.substring(cookieName.length + 1));\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t\treturn \"\";\n\t\t\t\t\t}\n\n\t\t\t\t\twindow.addEventListener(\"message\", function (e) {\n\t\t\t\t\t\tif (e.origin === window.location.origin || typeof e.data !== \"string\") {\n\t\t\t\t\t\t\treturn;\n\t\t\t\t\t\t}\n\t\t\t\t\t\tvar message = e.data.split(\" \");\n\t\t\t\t\t\tvar clientID = message[0];\n\t\t\t\t\t\tvar sessionState = message[1] || \"\";\n\t\t\t\t\t\tvar salt = sessionState.split(\".\")[1];\n\t\t\t\t\t\tif (!clientID || !salt) {\n\t\t\t\t\t\t\te.source.postMessage(\"error\", e.origin);\n\t\t\t\t\t\t\treturn;\n\t\t\t\t\t\t}\n\n\t\t\t\t\t\tvar data = new TextEncoder().encode(clientID + \" \" + e.origin + \" \" + getBrowserState() + \" \" + salt);\n\t\t\t\t\t\tcrypto.subtle.digest(\"SHA-256\", data).then(function (hash) {\n\t\t\t\t\t\t\tvar hex = Array.from(new Uint8Array(hash)).map(function (b) {\n\t\t\t\t\t\t\t\treturn b.toString(16).padStart(2, \"0\");\n\t\t\t\t\t\t\t}).join(\"\");\n\t\t\t\t\t\t\te.source.postMessage(hex + \".\" + salt === sessionState ? \"unchanged\" : \"changed\", e.origin);\n\t\t\t\t\t\t});\n\t\t\t\t\t});\n\t\t\t\t})();\n\t\t\t</script></body></html>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
@@ -59,87 +59,100 @@ func Page(title string, user *account.User) templ.Component {
 		ctx = templ.ClearChildren(ctx)
 		basePath := shared.GetBasePath(ctx)
 		appURL := shared.GetAppURL(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"en\" class=\"h-full bg-primary text-primary\"><head><meta charset=\"UTF-8\"><title>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var2 string
-		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(title)
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(shared.GetLocale(ctx))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 38, Col: 17}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 35, Col: 35}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><link rel=\"icon\" type=\"image/svg+xml\" href=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" class=\"h-full bg-primary text-primary\"><head><meta charset=\"UTF-8\"><title>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var3 string
-		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(basePath + "/dist/favicon.png")
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(title)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 39, Col: 78}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 38, Col: 17}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"><meta name=\"robots\" content=\"index, follow\"><meta name=\"revisit-after\" content=\"7 days\"><meta name=\"language\" content=\"English\"><!--\n\t\t<script src=\"https://unpkg.com/htmx.org@1.9.2\" integrity=\"sha384-L6OqL9pRWyyFU3+/bjdSri+iIphTN/bvYyM37tICVyOJkWZLpP2vGn6VUEXgzg6h\" crossorigin=\"anonymous\"></script>\n\t\t<script src=\"https://unpkg.com/hyperscript.org@0.9.8\"></script>\n\t\t<script src=\"https://unpkg.com/sortablejs@1.15.0\"></script>\n\t\t<script src={ basePath + \"/dist/js/app.js\" }></script>\n\t\t--><link rel=\"stylesheet\" href=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</title><link rel=\"icon\" type=\"image/svg+xml\" href=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var4 string
-		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(basePath + "/dist/styles.css")
+		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(basePath + "/dist/favicon.png")
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 50, Col: 62}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 39, Col: 78}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "\"><script src=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"><meta name=\"robots\" content=\"index, follow\"><meta name=\"revisit-after\" content=\"7 days\"><meta name=\"language\" content=\"English\"><!--\n\t\t<script src=\"https://unpkg.com/htmx.org@1.9.2\" integrity=\"sha384-L6OqL9pRWyyFU3+/bjdSri+iIphTN/bvYyM37tICVyOJkWZLpP2vGn6VUEXgzg6h\" crossorigin=\"anonymous\"></script>\n\t\t<script src=\"https://unpkg.com/hyperscript.org@0.9.8\"></script>\n\t\t<script src=\"https://unpkg.com/sortablejs@1.15.0\"></script>\n\t\t<script src={ basePath + \"/dist/js/app.js\" }></script>\n\t\t--><link rel=\"stylesheet\" href=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var5 string
-		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(basePath + "/dist/index.js")
+		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(basePath + "/dist/styles.css")
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 51, Col: 44}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 50, Col: 62}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "\" defer></script></head><body class=\"h-full\"><div class=\"fixed z-50 w-full bg-sidebar text-sidebar-foreground top-0 left-0 h-16\"><div class=\"h-full flex justify-between items-center m-auto max-w-5xl\"><div class=\"flex gap-2\"><a href=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "\"><script src=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var6 string
+		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(basePath + "/dist/index.js")
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 51, Col: 44}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\" defer></script></head><body class=\"h-full\"><div class=\"fixed z-50 w-full bg-sidebar text-sidebar-foreground top-0 left-0 h-16\"><div class=\"h-full flex justify-between items-center m-auto max-w-5xl\"><div class=\"flex gap-2\"><a href=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var6 templ.SafeURL = templ.SafeURL(basePath)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var6)))
+		var templ_7745c5c3_Var7 templ.SafeURL = templ.SafeURL(basePath)
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var7)))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\" class=\"flex items-center gap-2 self-center font-medium\"><div class=\"flex h-6 w-6 items-center justify-center rounded-md bg-primary text-primary-foreground\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"size-4\" width=\"24\" height=\"24\" viewBox=\"0 0 24 24\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\" class=\"lucide lucide-gallery-vertical-end-icon lucide-gallery-vertical-end\"><path d=\"M7 2h10\"></path><path d=\"M5 6h14\"></path><rect width=\"18\" height=\"12\" x=\"3\" y=\"10\" rx=\"2\"></rect></svg></div>GitClassrooms</a> ")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\" class=\"flex items-center gap-2 self-center font-medium\"><div class=\"flex h-6 w-6 items-center justify-center rounded-md bg-primary text-primary-foreground\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"size-4\" width=\"24\" height=\"24\" viewBox=\"0 0 24 24\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\" class=\"lucide lucide-gallery-vertical-end-icon lucide-gallery-vertical-end\"><path d=\"M7 2h10\"></path><path d=\"M5 6h14\"></path><rect width=\"18\" height=\"12\" x=\"3\" y=\"10\" rx=\"2\"></rect></svg></div>GitClassrooms</a> ")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if appURL != "" {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<a href=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<a href=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var7 templ.SafeURL = templ.SafeURL(appURL)
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var7)))
+			var templ_7745c5c3_Var8 templ.SafeURL = templ.SafeURL(appURL)
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var8)))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "\" class=\"flex items-center gap-2 self-center font-medium\"><div class=\"flex h-6 w-6 items-center justify-center rounded-md bg-primary text-primary-foreground\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"size-4\" width=\"24\" height=\"24\" viewBox=\"0 0 24 24\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\" class=\"lucide lucide-gallery-vertical-end-icon lucide-gallery-vertical-end\"><path d=\"M7 2h10\"></path><path d=\"M5 6h14\"></path><rect width=\"18\" height=\"12\" x=\"3\" y=\"10\" rx=\"2\"></rect></svg></div>GitClassrooms App</a>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "\" class=\"flex items-center gap-2 self-center font-medium\"><div class=\"flex h-6 w-6 items-center justify-center rounded-md bg-primary text-primary-foreground\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"size-4\" width=\"24\" height=\"24\" viewBox=\"0 0 24 24\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\" class=\"lucide lucide-gallery-vertical-end-icon lucide-gallery-vertical-end\"><path d=\"M7 2h10\"></path><path d=\"M5 6h14\"></path><rect width=\"18\" height=\"12\" x=\"3\" y=\"10\" rx=\"2\"></rect></svg></div>GitClassrooms App</a>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</div><div class=\"flex gap-4\"><div class=\"relative\"><button id=\"change-theme\" onclick=\"toggleDropdown(event)\" class=\"btn-outline dropdown-button\">Theme</button><div data-state=\"closed\" class=\"dropdown-content hidden\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</div><div class=\"flex gap-4\"><div class=\"relative\"><button id=\"change-theme\" onclick=\"toggleDropdown(event)\" class=\"btn-outline dropdown-button\">Theme</button><div data-state=\"closed\" class=\"dropdown-content hidden\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -148,79 +161,79 @@ func Page(title string, user *account.User) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<button class=\"btn-ghost justify-start w-full py-0\" onclick=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "<button class=\"btn-ghost justify-start w-full py-0\" onclick=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var8 templ.ComponentScript = templ.JSFuncCall("setTheme", theme.name)
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var8.Call)
+			var templ_7745c5c3_Var9 templ.ComponentScript = templ.JSFuncCall("setTheme", theme.name)
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var9.Call)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var9 string
-			templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(theme.text)
+			var templ_7745c5c3_Var10 string
+			templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(theme.text)
 			if templ_7745c5c3_Err != nil {
 				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 78, Col: 22}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</button>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</button>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</div></div><div class=\"relative\"><button id=\"change-mode\" onclick=\"toggleDropdown(event)\" class=\"btn-outline dropdown-button\">Theme</button><div data-state=\"closed\" class=\"dropdown-content z-10 hidden\"><button class=\"btn-ghost justify-start w-full py-0\" onclick=\"setMode(&#39;light&#39;)\">Ligth</button> <button class=\"btn-ghost justify-start w-full py-0\" onclick=\"setMode(&#39;dark&#39;)\">Dark</button> <button class=\"btn-ghost justify-start w-full py-0\" onclick=\"setMode(&#39;system&#39;)\">System</button></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</div></div><div class=\"relative\"><button id=\"change-mode\" onclick=\"toggleDropdown(event)\" class=\"btn-outline dropdown-button\">Theme</button><div data-state=\"closed\" class=\"dropdown-content z-10 hidden\"><button class=\"btn-ghost justify-start w-full py-0\" onclick=\"setMode(&#39;light&#39;)\">Ligth</button> <button class=\"btn-ghost justify-start w-full py-0\" onclick=\"setMode(&#39;dark&#39;)\">Dark</button> <button class=\"btn-ghost justify-start w-full py-0\" onclick=\"setMode(&#39;system&#39;)\">System</button></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if user != nil {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "<div class=\"relative\"><li onclick=\"toggleDropdown(event)\" class=\"avatar dropdown-button\"><img onerror=\"this.parentNode.innerHTML=`&lt;div class=&#39;avatar-fallback text-primary-foreground dropdown-button&#39;&gt;TT&lt;/div&gt;`\" class=\"avatar-image dropdown-button\" src=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<div class=\"relative\"><li onclick=\"toggleDropdown(event)\" class=\"avatar dropdown-button\"><img onerror=\"this.parentNode.innerHTML=`&lt;div class=&#39;avatar-fallback text-primary-foreground dropdown-button&#39;&gt;TT&lt;/div&gt;`\" class=\"avatar-image dropdown-button\" src=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var10 string
-			templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(user.Picture)
+			var templ_7745c5c3_Var11 string
+			templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(user.Picture)
 			if templ_7745c5c3_Err != nil {
 				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 94, Col: 185}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "\"></li><div data-state=\"closed\" class=\"dropdown-content hidden\"><p class=\"py-2 px-4 text-sm font-bold\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\"></li><div data-state=\"closed\" class=\"dropdown-content hidden\"><p class=\"py-2 px-4 text-sm font-bold\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var11 string
-			templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs("@" + user.Username)
+			var templ_7745c5c3_Var12 string
+			templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs("@" + user.Username)
 			if templ_7745c5c3_Err != nil {
 				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/page.templ`, Line: 97, Col: 69}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "</p><a href=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "</p><a href=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var12 templ.SafeURL = templ.SafeURL(basePath + "/logout")
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var12)))
+			var templ_7745c5c3_Var13 templ.SafeURL = templ.SafeURL(basePath + "/logout")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var13)))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "\" class=\"btn-ghost justify-start w-full py-0\">Logout</a></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "\" class=\"btn-ghost justify-start w-full py-0\">Logout</a></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "</div></div></div><div class=\"fixed empty:hidden bg-sidebar top-16 left-[50%] translate-x-[-50%] z-40 p-2 rounded-b-xl w-full max-w-3xl flex flex-col gap-2\"></div><div class=\"min-h-svh pt-22 md:pt-26 flex flex-col items-center gap-6 bg-muted px-6 md:px-10\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</div></div></div><div class=\"fixed empty:hidden bg-sidebar top-16 left-[50%] translate-x-[-50%] z-40 p-2 rounded-b-xl w-full max-w-3xl flex flex-col gap-2\"></div><div class=\"min-h-svh pt-22 md:pt-26 flex flex-col items-center gap-6 bg-muted px-6 md:px-10\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -228,7 +241,7 @@ func Page(title string, user *account.User) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</div><script>\n\t\t\t// Dropdown Script\n\t\t\tfunction toggleDropdown(e) {\n\t\t\t\tconst elem = e.currentTarget.parentNode.querySelector(\".dropdown-content\")\n\t\t\t\tif (elem.dataset.state === \"closed\") {\n\t\t\t\t\telem.classList.remove(\"hidden\");\n\t\t\t\t\telem.dataset.state = \"open\"\n\t\t\t\t} else {\n\t\t\t\t\telem.dataset.state = \"closed\"\n\t\t\t\t\tsetTimeout(() => elem.classList.add(\"hidden\"), 100);\n\t\t\t\t}\n\t\t\t}\n\n\t\t\twindow.addEventListener(\"click\", (event) => {\n\t\t\t  if (!event.target.matches(\".dropdown-button\")) {\n\t\t\t    document.querySelectorAll(\".dropdown-content\").forEach(d => {\n\t\t\t\t\td.dataset.state = \"closed\"\n\t\t\t\t\tsetTimeout(() => d.classList.add(\"hidden\"), 100);\n\t\t\t\t});\n\t\t\t  }\n\t\t\t})\n\t\t\t</script><script>\n\t\t\t// New Modal Script\n\t\t\tfunction openModal(e) {\n\t\t\t\tconst modal = e.currentTarget.nextSibling\n\t\t\t\tif (modal.dataset.state === \"closed\") {\n\t\t\t\t\tmodal.dataset.state = \"open\"\n\t\t\t\t\tmodal.querySelector(\".modal-content\").dataset.state = \"open\"\n\t\t\t\t\tmodal.classList.remove(\"hidden\")\n\t\t\t\t\tdocument.body.dataset.modal=\"open\"\n\t\t\t\t}\n\t\t\t}\n\n\t\t\tfunction closeModal() {\n\t\t\t\tdocument.querySelectorAll(\".modal\").forEach((modal) => {\n\t\t\t\t\tif (modal.dataset.state === \"closed\") return\n\t\t\t\t\tmodal.dataset.state = \"closed\"\n\t\t\t\t\tmodal.querySelector(\".modal-content\").dataset.state = \"closed\"\n\t\t\t\t\tsetTimeout(() => modal.classList.add(\"hidden\"), 100)\n\t\t\t\t\tdocument.body.dataset.modal=\"\"\n\t\t\t\t})\n\t\t\t}\n\n\t\t\twindow.addEventListener('click', event => {\n\t\t\t\tif (event.target.classList.contains('modal')) {\n\t\t\t\t\tcloseModal()\n\t\t\t\t}\n\t\t\t});\n\n\t\t\twindow.addEventListener('keydown', event => {\n\t\t\t\tif (event.key === \"Escape\" && document.body.dataset.modal == \"open\") {\n\t\t\t\t\tcloseModal()\n\t\t\t\t}\n\t\t\t});\n\t\t\t</script></body></html>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "</div><script>\n\t\t\t// Dropdown Script\n\t\t\tfunction toggleDropdown(e) {\n\t\t\t\tconst elem = e.currentTarget.parentNode.querySelector(\".dropdown-content\")\n\t\t\t\tif (elem.dataset.state === \"closed\") {\n\t\t\t\t\telem.classList.remove(\"hidden\");\n\t\t\t\t\telem.dataset.state = \"open\"\n\t\t\t\t} else {\n\t\t\t\t\telem.dataset.state = \"closed\"\n\t\t\t\t\tsetTimeout(() => elem.classList.add(\"hidden\"), 100);\n\t\t\t\t}\n\t\t\t}\n\n\t\t\twindow.addEventListener(\"click\", (event) => {\n\t\t\t  if (!event.target.matches(\".dropdown-button\")) {\n\t\t\t    document.querySelectorAll(\".dropdown-content\").forEach(d => {\n\t\t\t\t\td.dataset.state = \"closed\"\n\t\t\t\t\tsetTimeout(() => d.classList.add(\"hidden\"), 100);\n\t\t\t\t});\n\t\t\t  }\n\t\t\t})\n\t\t\t</script><script>\n\t\t\t// New Modal Script\n\t\t\tfunction openModal(e) {\n\t\t\t\tconst modal = e.currentTarget.nextSibling\n\t\t\t\tif (modal.dataset.state === \"closed\") {\n\t\t\t\t\tmodal.dataset.state = \"open\"\n\t\t\t\t\tmodal.querySelector(\".modal-content\").dataset.state = \"open\"\n\t\t\t\t\tmodal.classList.remove(\"hidden\")\n\t\t\t\t\tdocument.body.dataset.modal=\"open\"\n\t\t\t\t}\n\t\t\t}\n\n\t\t\tfunction closeModal() {\n\t\t\t\tdocument.querySelectorAll(\".modal\").forEach((modal) => {\n\t\t\t\t\tif (modal.dataset.state === \"closed\") return\n\t\t\t\t\tmodal.dataset.state = \"closed\"\n\t\t\t\t\tmodal.querySelector(\".modal-content\").dataset.state = \"closed\"\n\t\t\t\t\tsetTimeout(() => modal.classList.add(\"hidden\"), 100)\n\t\t\t\t\tdocument.body.dataset.modal=\"\"\n\t\t\t\t})\n\t\t\t}\n\n\t\t\twindow.addEventListener('click', event => {\n\t\t\t\tif (event.target.classList.contains('modal')) {\n\t\t\t\t\tcloseModal()\n\t\t\t\t}\n\t\t\t});\n\n\t\t\twindow.addEventListener('keydown', event => {\n\t\t\t\tif (event.key === \"Escape\" && document.body.dataset.modal == \"open\") {\n\t\t\t\t\tcloseModal()\n\t\t\t\t}\n\t\t\t});\n\t\t\t</script></body></html>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -5,6 +5,7 @@ import "context"
 type basePathCtxKey struct{}
 type appURLCtxKey struct{}
 type pathCtxKey struct{}
+type localeCtxKey struct{}
 
 func GetBasePath(ctx context.Context) string {
 	if basePath, ok := ctx.Value(basePathCtxKey{}).(string); ok {
@@ -38,3 +39,14 @@ func GetURLPath(ctx context.Context) string {
 func SetPath(ctx context.Context, path string) context.Context {
 	return context.WithValue(ctx, pathCtxKey{}, path)
 }
+
+func GetLocale(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeCtxKey{}).(string); ok {
+		return locale
+	}
+	return "en"
+}
+
+func SetLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeCtxKey{}, locale)
+}
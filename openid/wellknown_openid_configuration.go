@@ -2,9 +2,12 @@ package openid
 
 import (
 	"encoding/json"
+	"maps"
 	"net/http"
+	"slices"
 
 	"github.com/akatranlp/sentinel/jose"
+	"github.com/akatranlp/sentinel/openid/enums"
 )
 
 func (ip *IdentitiyProvider) WellKnownOpenIDConfiguration(w http.ResponseWriter, r *http.Request) {
@@ -12,6 +15,49 @@ func (ip *IdentitiyProvider) WellKnownOpenIDConfiguration(w http.ResponseWriter,
 	j := jose.GetJose(ctx)
 	openIDConfig := j.GetOpenIDConfiguration()
 
+	// The registration endpoint is only advertised if clients can register themselves
+	if len(ip.initialAccessTokens) > 0 {
+		openIDConfig.RegistrationEndpoint = j.Issuer() + "/oauth/register"
+	}
+
+	// Custom scopes and claims of the release policy are advertised as well
+	for _, scope := range slices.Sorted(maps.Keys(ip.scopeClaims)) {
+		if !slices.Contains(openIDConfig.ScopesSupported, scope) {
+			openIDConfig.ScopesSupported = append(openIDConfig.ScopesSupported, scope)
+		}
+		for _, claim := range ip.scopeClaims[scope] {
+			if !slices.Contains(openIDConfig.ClaimsSupported, enums.Claim(claim)) {
+				openIDConfig.ClaimsSupported = append(openIDConfig.ClaimsSupported, enums.Claim(claim))
+			}
+		}
+	}
+
+	for _, p := range ip.providers {
+		if acr := p.GetAuthContext().ACR; acr != "" && !slices.Contains(openIDConfig.ACRValuesSupported, acr) {
+			openIDConfig.ACRValuesSupported = append(openIDConfig.ACRValuesSupported, acr)
+		}
+	}
+	slices.Sort(openIDConfig.ACRValuesSupported)
+
+	// Pairwise subjects are only supported with a configured salt
+	if len(ip.pairwiseSubjectSalt) == 0 {
+		openIDConfig.SubjectTypesSupported = []enums.SubjectType{enums.SubjectTypePublic}
+	}
+
+	// Certificate-bound access tokens need the client certificate, which is only requested when we serve TLS
+	openIDConfig.TLSClientCertificateBoundAccessTokens = ip.tlsCertFile != ""
+
+	// Only the enabled client authentication methods are advertised
+	openIDConfig.TokenEndpointAuthMethodsSupported = ip.clientAuthMethods
+	openIDConfig.IntrospectionEndpointAuthMethodsSupported = ip.clientAuthMethods
+	openIDConfig.RevocationEndpointAuthMethodsSupported = ip.clientAuthMethods
+	for _, alg := range enums.SigningAlgValueValues() {
+		if alg.IsSymmetric() && slices.Contains(ip.clientAuthMethods, enums.EndpointAuthMethodClientSecretJwt) ||
+			!alg.IsSymmetric() && slices.Contains(ip.clientAuthMethods, enums.EndpointAuthMethodPrivateKeyJwt) {
+			openIDConfig.TokenEndpointAuthSigningAlgValuesSupported = append(openIDConfig.TokenEndpointAuthSigningAlgValuesSupported, alg)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(openIDConfig)
 }
@@ -0,0 +1,61 @@
+package openid
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+	"github.com/akatranlp/sentinel/openid/types"
+)
+
+func TestWellKnownOpenIDConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []OptionFn
+		wantTLS bool
+	}{
+		{
+			name: "without tls",
+		},
+		{
+			name:    "with tls",
+			opts:    []OptionFn{WithTLS("cert.pem", "key.pem")},
+			wantTLS: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := newTestProvider(t, tt.opts...)
+			w := httptest.NewRecorder()
+			ip.WellKnownOpenIDConfiguration(w, newTestRequest(t, ip, http.MethodGet, "/.well-known/openid-configuration", nil))
+
+			var config types.OpenIDConfiguration
+			if err := json.NewDecoder(w.Body).Decode(&config); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, claim := range []enums.Claim{enums.ClaimCnf, enums.ClaimHtm, enums.ClaimHtu, enums.ClaimAth, enums.ClaimEvents, enums.ClaimAct, enums.ClaimClientId, enums.ClaimTokenType} {
+				if slices.Contains(config.ClaimsSupported, claim) {
+					t.Errorf("expected the protocol claim %s not to be advertised", claim)
+				}
+			}
+			for _, claim := range []enums.Claim{enums.ClaimSub, enums.ClaimEmail, enums.ClaimName} {
+				if !slices.Contains(config.ClaimsSupported, claim) {
+					t.Errorf("expected the claim %s to be advertised", claim)
+				}
+			}
+
+			if config.TLSClientCertificateBoundAccessTokens != tt.wantTLS {
+				t.Errorf("expected tls_client_certificate_bound_access_tokens %t, got %t", tt.wantTLS, config.TLSClientCertificateBoundAccessTokens)
+			}
+			hasTLSMethod := slices.ContainsFunc(config.TokenEndpointAuthMethodsSupported, isTLSClientAuthMethod)
+			if hasTLSMethod != tt.wantTLS {
+				t.Errorf("expected the mutual-TLS methods to be advertised %t, got %t", tt.wantTLS, hasTLSMethod)
+			}
+		})
+	}
+}
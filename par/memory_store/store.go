@@ -0,0 +1,56 @@
+package memorystore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akatranlp/sentinel/par"
+)
+
+type MemoryPushedRequestStore struct {
+	requests map[string]par.PushedRequest
+	mx       sync.Mutex
+}
+
+var _ (par.PushedRequestStore) = (*MemoryPushedRequestStore)(nil)
+
+func NewMemoryPushedRequestStore() (*MemoryPushedRequestStore, error) {
+	return &MemoryPushedRequestStore{
+		requests: make(map[string]par.PushedRequest),
+	}, nil
+}
+
+func (s *MemoryPushedRequestStore) CreatePushedRequest(ctx context.Context, req par.PushedRequest) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	// pushed requests are short-lived, so the expired ones are dropped here instead of in a cleanup job
+	curr := time.Now()
+	for requestURI, pushed := range s.requests {
+		if pushed.Expiry.Before(curr) {
+			delete(s.requests, requestURI)
+		}
+	}
+	s.requests[req.RequestURI] = req
+	return nil
+}
+
+func (s *MemoryPushedRequestStore) GetPushedRequest(ctx context.Context, requestURI string) (par.PushedRequest, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	req, ok := s.requests[requestURI]
+	if !ok || req.Expiry.Before(time.Now()) {
+		return par.PushedRequest{}, par.ErrPushedRequestNotFound
+	}
+	return req, nil
+}
+
+func (s *MemoryPushedRequestStore) DeletePushedRequest(ctx context.Context, requestURI string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if _, ok := s.requests[requestURI]; !ok {
+		return par.ErrPushedRequestNotFound
+	}
+	delete(s.requests, requestURI)
+	return nil
+}
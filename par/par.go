@@ -0,0 +1,30 @@
+package par
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akatranlp/sentinel/openid/types"
+)
+
+var (
+	ErrPushedRequestNotFound = errors.New("pushed authorization request not found")
+)
+
+// PushedRequest is an authorization request, which the client pushed to the par endpoint (RFC 9126)
+type PushedRequest struct {
+	RequestURI  string
+	AuthRequest types.AuthRequest
+	Expiry      time.Time
+}
+
+type PushedRequestStore interface {
+	CreatePushedRequest(ctx context.Context, req PushedRequest) error
+	// GetPushedRequest returns the request without removing it, so it can be checked first.
+	// Expired requests are not returned.
+	GetPushedRequest(ctx context.Context, requestURI string) (PushedRequest, error)
+	// DeletePushedRequest removes the request and fails with ErrPushedRequestNotFound if it was already removed,
+	// so a request_uri can only be used once, even by multiple instances.
+	DeletePushedRequest(ctx context.Context, requestURI string) error
+}
@@ -30,11 +30,21 @@ type Provider interface {
 	RefreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error)
 	GetType() ProviderType
 	GetIconURL() string
+	GetAuthContext() AuthContext
 
 	GetName() string
 	GetSlug() string
 }
 
+// AuthContext declares the authentication context class reference (acr)
+// and the authentication methods (amr) a login with the provider satisfies
+type AuthContext struct {
+	ACR string
+	AMR []string
+}
+
+func (a AuthContext) GetAuthContext() AuthContext { return a }
+
 var _ Provider = (*OIDCProvider)(nil)
 var _ Provider = (*OauthProvider)(nil)
 
@@ -48,6 +58,7 @@ type OIDCProvider struct {
 	Verifier     *oidc.IDTokenVerifier
 	Scopes       []string
 	IconURL      string
+	AuthContext
 }
 
 func NewGitLabProvider(name, slug, clientID, clientSecret, baseURL string) (*OIDCProvider, error) {
@@ -225,6 +236,7 @@ type OauthProvider struct {
 	UserInfoGetter UserInfoGetter
 	Scopes         []string
 	IconURL        string
+	AuthContext
 }
 
 type UserInfoGetter = func(ctx context.Context, token oauth2.TokenSource) (*account.Account, error)
@@ -365,18 +377,41 @@ type FactoryParams struct {
 	IconURL      string
 	ClientID     string
 	ClientSecret string
+	ACR          string
+	AMR          []string
 }
 
 func ProviderFactory(params FactoryParams) (Provider, error) {
+	authContext := AuthContext{ACR: params.ACR, AMR: params.AMR}
 	switch params.Type {
 	case "github":
-		return NewGitHubProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret)
+		p, err := NewGitHubProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret)
+		if err != nil {
+			return nil, err
+		}
+		p.AuthContext = authContext
+		return p, nil
 	case "gitea":
-		return NewGiteaProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret, params.BaseURL)
+		p, err := NewGiteaProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret, params.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		p.AuthContext = authContext
+		return p, nil
 	case "gitlab":
-		return NewGitLabProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret, params.BaseURL)
+		p, err := NewGitLabProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret, params.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		p.AuthContext = authContext
+		return p, nil
 	case "keycloak":
-		return NewKeycloakProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret, params.BaseURL, params.IconURL)
+		p, err := NewKeycloakProvider(params.Name, params.Slug, params.ClientID, params.ClientSecret, params.BaseURL, params.IconURL)
+		if err != nil {
+			return nil, err
+		}
+		p.AuthContext = authContext
+		return p, nil
 	default:
 		return nil, errors.New("no valid provider type")
 	}
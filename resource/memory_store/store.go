@@ -0,0 +1,107 @@
+package memorystore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/akatranlp/sentinel/resource"
+)
+
+type MemoryResourceStore struct {
+	// resources are stored by their uri
+	resources map[string]resource.Resource
+	mx        sync.Mutex
+	savePath  string
+}
+
+type marshal struct {
+	Resources map[string]resource.Resource `json:"resources"`
+}
+
+func (s *MemoryResourceStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(marshal{
+		Resources: s.resources,
+	})
+}
+
+func (s *MemoryResourceStore) UnmarshalJSON(data []byte) error {
+	var store marshal
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return err
+	}
+
+	*s = MemoryResourceStore{
+		resources: store.Resources,
+	}
+	return nil
+}
+
+var _ (resource.ResourceStore) = (*MemoryResourceStore)(nil)
+
+func NewMemoryResourceStore(filePath ...string) (*MemoryResourceStore, error) {
+	var path string
+	if len(filePath) > 0 {
+		path = filePath[0]
+	}
+	if path != "" {
+		f, err := os.Open(path)
+		if err == nil {
+			defer f.Close()
+			var store MemoryResourceStore
+			if err := json.NewDecoder(f).Decode(&store); err != nil {
+				return nil, err
+			}
+			store.savePath = path
+			return &store, nil
+		}
+	}
+	return &MemoryResourceStore{
+		resources: make(map[string]resource.Resource),
+		savePath:  path,
+	}, nil
+}
+
+func (s *MemoryResourceStore) GetResource(ctx context.Context, uri string) (resource.Resource, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	res, ok := s.resources[uri]
+	if !ok {
+		return resource.Resource{}, resource.ErrResourceNotFound
+	}
+	return res, nil
+}
+
+func (s *MemoryResourceStore) SetResource(ctx context.Context, res resource.Resource) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.resources[res.URI] = res
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryResourceStore) DeleteResource(ctx context.Context, uri string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if _, ok := s.resources[uri]; !ok {
+		return resource.ErrResourceNotFound
+	}
+	delete(s.resources, uri)
+	s.saveToFile()
+	return nil
+}
+
+func (s *MemoryResourceStore) saveToFile() error {
+	if s.savePath == "" {
+		return nil
+	}
+	f, err := os.Create(s.savePath)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
@@ -0,0 +1,35 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/akatranlp/sentinel/openid/enums"
+)
+
+var (
+	ErrResourceNotFound = errors.New("resource not found")
+)
+
+// Resource is a protected API, which accepts the access tokens of the identity provider (RFC 8707)
+type Resource struct {
+	// URI is the resource indicator clients request and the audience of the access tokens
+	URI string
+	// Scope are the scopes the resource accepts, the access tokens are restricted to them
+	Scope enums.Scopes
+	// ClientIDs restricts the clients which can request access tokens for the resource, if it is set
+	ClientIDs []string
+}
+
+// AllowsClient reports if the client can request access tokens for the resource
+func (r *Resource) AllowsClient(clientID string) bool {
+	return len(r.ClientIDs) == 0 || slices.Contains(r.ClientIDs, clientID)
+}
+
+type ResourceStore interface {
+	GetResource(ctx context.Context, uri string) (Resource, error)
+	// SetResource creates the resource or replaces the one with the same uri
+	SetResource(ctx context.Context, res Resource) error
+	DeleteResource(ctx context.Context, uri string) error
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"time"
+)
+
+// BrowserStateCookieName is the cookie, which the check session iframe reads the browser state from
+func (sm *SessionManager) BrowserStateCookieName() string {
+	return sm.sessionName + "-browser-state"
+}
+
+func (sm *SessionManager) GetBrowserState(ctx context.Context) string {
+	return sm.GetString(ctx, browserStateKey)
+}
+
+func (sm *SessionManager) renewBrowserState(ctx context.Context) {
+	sm.Put(ctx, browserStateKey, rand.Text())
+}
+
+// BrowserStateMiddleware mirrors the browser state of the session into a cookie which is readable by javascript.
+// It has to be used after LoadAndSave, so the session is loaded when the response is written.
+func (sm *SessionManager) BrowserStateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&browserStateWriter{ResponseWriter: w, r: r, sm: sm}, r)
+	})
+}
+
+func (sm *SessionManager) writeBrowserStateCookie(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cookie := &http.Cookie{
+		Name:   sm.BrowserStateCookieName(),
+		Path:   sm.Cookie.Path,
+		Domain: sm.Cookie.Domain,
+		Secure: sm.Cookie.Secure,
+		// The cookie is read by the check session iframe, which is embedded by the clients
+		HttpOnly: false,
+		SameSite: http.SameSiteNoneMode,
+	}
+	// Browsers drop SameSite=None cookies without Secure, so plain http setups only work for iframes of the same site
+	if !cookie.Secure {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+
+	browserState := sm.GetBrowserState(ctx)
+	if browserState == "" {
+		if _, err := r.Cookie(cookie.Name); err != nil {
+			return
+		}
+		cookie.MaxAge = -1
+	} else {
+		cookie.Value = browserState
+		cookie.Expires = sm.Deadline(ctx)
+		if idleExpiry := time.Now().Add(sm.IdleTimeout); sm.IdleTimeout > 0 && idleExpiry.Before(cookie.Expires) {
+			cookie.Expires = idleExpiry
+		}
+	}
+	http.SetCookie(w, cookie)
+}
+
+type browserStateWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	sm      *SessionManager
+	written bool
+}
+
+func (bw *browserStateWriter) WriteHeader(code int) {
+	if !bw.written {
+		bw.written = true
+		bw.sm.writeBrowserStateCookie(bw.ResponseWriter, bw.r)
+	}
+	bw.ResponseWriter.WriteHeader(code)
+}
+
+func (bw *browserStateWriter) Write(b []byte) (int, error) {
+	if !bw.written {
+		bw.WriteHeader(http.StatusOK)
+	}
+	return bw.ResponseWriter.Write(b)
+}
+
+func (bw *browserStateWriter) Unwrap() http.ResponseWriter {
+	return bw.ResponseWriter
+}
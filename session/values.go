@@ -15,7 +15,14 @@ const (
 
 	authKey     = "auth"
 	authTimeKey = "auth-time"
+	authACRKey  = "auth-acr"
+	authAMRKey  = "auth-amr"
 	authReqKey  = "auth-req"
+	logoutKey   = "logout"
+
+	browserStateKey = "browser-state"
+
+	clientSessionsKey = "client-sessions"
 
 	providerVerifier = "verifier"
 )
@@ -32,7 +39,11 @@ func (sm *SessionManager) SetAuth(ctx context.Context, id string) {
 	if id == "" {
 		sm.Remove(ctx, authKey)
 		sm.Remove(ctx, authTimeKey)
+		sm.Remove(ctx, authACRKey)
+		sm.Remove(ctx, authAMRKey)
+		sm.Remove(ctx, browserStateKey)
 	} else {
+		sm.renewBrowserState(ctx)
 		sm.Put(ctx, authTimeKey, time.Now())
 		sm.Put(ctx, authKey, id)
 	}
@@ -46,7 +57,22 @@ func (sm *SessionManager) GetAuth(ctx context.Context) string {
 }
 
 func (sm *SessionManager) GetAuthTime(ctx context.Context) time.Time {
-	return sm.GetTime(ctx, authKey)
+	return sm.GetTime(ctx, authTimeKey)
+}
+
+// SetAuthContext records how the user authenticated, so clients can demand a stronger login
+func (sm *SessionManager) SetAuthContext(ctx context.Context, acr string, amr []string) {
+	sm.Put(ctx, authACRKey, acr)
+	sm.Put(ctx, authAMRKey, amr)
+}
+
+func (sm *SessionManager) GetACR(ctx context.Context) string {
+	return sm.GetString(ctx, authACRKey)
+}
+
+func (sm *SessionManager) GetAMR(ctx context.Context) []string {
+	amr, _ := sm.Get(ctx, authAMRKey).([]string)
+	return amr
 }
 
 func (sm *SessionManager) IsAuthed(ctx context.Context) bool {
@@ -57,11 +83,36 @@ func (sm *SessionManager) SetAuthRequest(ctx context.Context, authReq types.Auth
 	sm.Put(ctx, authReqKey, authReq)
 }
 
+// PeekAuthRequest returns the pending authorization request without removing it from the session
+func (sm *SessionManager) PeekAuthRequest(ctx context.Context) (types.AuthRequest, bool) {
+	authReq, ok := sm.Get(ctx, authReqKey).(types.AuthRequest)
+	return authReq, ok
+}
+
 func (sm *SessionManager) GetAuthRequest(ctx context.Context) (types.AuthRequest, bool) {
 	authReq, ok := sm.Pop(ctx, authReqKey).(types.AuthRequest)
 	return authReq, ok
 }
 
+func (sm *SessionManager) SetPendingLogout(ctx context.Context, logout types.PendingLogout) {
+	sm.Put(ctx, logoutKey, logout)
+}
+
+func (sm *SessionManager) GetPendingLogout(ctx context.Context) (types.PendingLogout, bool) {
+	logout, ok := sm.Pop(ctx, logoutKey).(types.PendingLogout)
+	return logout, ok
+}
+
+// AddClientSession remembers that the client got tokens in this browser session, so it can be logged out with it
+func (sm *SessionManager) AddClientSession(ctx context.Context, clientSession types.ClientSession) {
+	sm.Put(ctx, clientSessionsKey, append(sm.GetClientSessions(ctx), clientSession))
+}
+
+func (sm *SessionManager) GetClientSessions(ctx context.Context) []types.ClientSession {
+	clientSessions, _ := sm.Get(ctx, clientSessionsKey).([]types.ClientSession)
+	return clientSessions
+}
+
 func (sm *SessionManager) SetVerifier(ctx context.Context, verifier types.Verifier) {
 	sm.Put(ctx, providerVerifier, verifier)
 }
@@ -74,5 +125,11 @@ func (sm *SessionManager) GetVerifier(ctx context.Context) (types.Verifier, bool
 func init() {
 	gob.Register(types.AuthRequest{})
 	gob.Register(types.Verifier{})
+	gob.Register(types.PendingLogout{})
+	// The values of the claims request are decoded from json
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+	gob.Register([]types.ClientSession{})
 	gob.Register(time.Time{})
+	gob.Register([]string{})
 }
@@ -3,6 +3,7 @@ package basestore
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/akatranlp/sentinel/token"
@@ -30,6 +31,10 @@ type SessionGetter interface {
 	GetAllSessions(ctx context.Context) ([]token.Session, error)
 }
 
+type UserSessionGetter interface {
+	GetSessionsByUserID(ctx context.Context, userID string) ([]token.Session, error)
+}
+
 type BaseTokenStore struct {
 	repo Repository
 }
@@ -54,19 +59,14 @@ func NewBaseTokenStore(repo Repository) (*BaseTokenStore, error) {
 	}, nil
 }
 
-func (s *BaseTokenStore) SetSession(ctx context.Context, sid, jti string, expiry time.Time) error {
-	sess := token.Session{
-		SessionID:  sid,
-		RefreshJTI: jti,
-		Expiry:     expiry,
-	}
+func (s *BaseTokenStore) SetSession(ctx context.Context, sess token.Session) error {
 	if spec, ok := s.repo.(SessionSetter); ok {
 		return spec.SetSession(ctx, sess)
 	}
 
 	spec := s.repo.(SessionCreater)
 
-	_, err := s.repo.GetSessionByID(ctx, sid)
+	_, err := s.repo.GetSessionByID(ctx, sess.SessionID)
 	if err == nil {
 		return s.repo.UpdateSession(ctx, sess)
 	} else if !errors.Is(err, token.ErrSessionNotFound) {
@@ -89,6 +89,26 @@ func (s *BaseTokenStore) GetSession(ctx context.Context, sid string) (token.Sess
 	return sess, nil
 }
 
+func (s *BaseTokenStore) GetSessionsByUserID(ctx context.Context, userID string) ([]token.Session, error) {
+	var sessions []token.Session
+	var err error
+	if spec, ok := s.repo.(UserSessionGetter); ok {
+		sessions, err = spec.GetSessionsByUserID(ctx, userID)
+	} else if spec, ok := s.repo.(SessionGetter); ok {
+		sessions, err = spec.GetAllSessions(ctx)
+	} else {
+		return nil, errors.New("repo must be user session getter or getter")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	curr := time.Now()
+	return slices.DeleteFunc(sessions, func(sess token.Session) bool {
+		return sess.UserID != userID || sess.Expiry.Before(curr)
+	}), nil
+}
+
 func (s *BaseTokenStore) RevokeSession(ctx context.Context, sid string) error {
 	return s.repo.DeleteSessionByID(ctx, sid)
 }
@@ -64,14 +64,10 @@ func NewMemoryTokenStore(filePath ...string) (*MemoryTokenStore, error) {
 	}, nil
 }
 
-func (s *MemoryTokenStore) SetSession(ctx context.Context, sid, jti string, expiry time.Time) error {
+func (s *MemoryTokenStore) SetSession(ctx context.Context, sess token.Session) error {
 	s.mx.Lock()
 	defer s.mx.Unlock()
-	s.sessions[sid] = token.Session{
-		SessionID:  sid,
-		RefreshJTI: jti,
-		Expiry:     expiry,
-	}
+	s.sessions[sess.SessionID] = sess
 	s.saveToFile()
 	return nil
 }
@@ -91,6 +87,19 @@ func (s *MemoryTokenStore) GetSession(ctx context.Context, sid string) (token.Se
 	return sess, nil
 }
 
+func (s *MemoryTokenStore) GetSessionsByUserID(ctx context.Context, userID string) ([]token.Session, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	curr := time.Now()
+	var sessions []token.Session
+	for _, sess := range s.sessions {
+		if sess.UserID == userID && sess.Expiry.After(curr) {
+			sessions = append(sessions, sess)
+		}
+	}
+	return sessions, nil
+}
+
 func (s *MemoryTokenStore) revokeSession(_ context.Context, sid string) error {
 	delete(s.sessions, sid)
 	s.saveToFile()
@@ -13,11 +13,29 @@ var (
 type Session struct {
 	SessionID  string
 	RefreshJTI string
+	UserID     string
+	ClientID   string
 	Expiry     time.Time
+	// AuthTime, ACR and AMR describe the login of the user,
+	// so the access tokens of a refresh carry them as well
+	AuthTime time.Time
+	ACR      string
+	AMR      []string
+	// Resources were granted in the authorization request, the access tokens of a refresh are restricted to them
+	Resources []string
+	// Claims is the claims request of the authorization as json,
+	// which selects the claims of the userinfo response
+	Claims string
+}
+
+// IsOffline reports if the session was granted offline_access, only these sessions get a refresh token
+func (s Session) IsOffline() bool {
+	return s.RefreshJTI != ""
 }
 
 type TokenStore interface {
-	SetSession(ctx context.Context, sid string, jti string, expiry time.Time) error
+	SetSession(ctx context.Context, sess Session) error
 	GetSession(ctx context.Context, sid string) (Session, error)
+	GetSessionsByUserID(ctx context.Context, userID string) ([]Session, error)
 	RevokeSession(ctx context.Context, sid string) error
 }
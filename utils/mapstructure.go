@@ -37,6 +37,7 @@ func CreateDecoderConfig() mapstructure.DecoderConfig {
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
 			mapstructure.TextUnmarshallerHookFunc(),
 			BinaryUnmarshallerHookFunc(),
+			mapstructure.StringToIntHookFunc(),
 		),
 		ErrorUnused: true,
 		ZeroFields:  true,